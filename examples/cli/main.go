@@ -1,30 +1,55 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 
 	"github.com/alexflint/go-arg"
 	"github.com/mikelorant/easyredir/pkg/easyredir"
+	"github.com/mikelorant/easyredir/pkg/easyredir/backup"
 	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/lint"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/mikelorant/easyredir/pkg/easyredir/reconcile"
 	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+	"github.com/mikelorant/easyredir/pkg/easyredir/simulator"
 )
 
 type CreateCmd struct {
 	Rule *struct {
 		ForwardParams *bool              `arg:"--forward-params" default:"false"`
 		ForwardPath   *bool              `arg:"--forward-path" default:"false"`
-		ResponseType  *rule.ResponseType `arg:"--response-type", default:"moved_permanently"`
+		ResponseType  *rule.ResponseType `arg:"--response-type" default:"moved_permanently"`
 		SourceURLs    []string           `arg:"--source-url,required"`
 		TargetURL     *string            `arg:"--target-url,required"`
 	} `arg:"subcommand:rule"`
 }
 
+type ExportCmd struct {
+	File string `arg:"-f,--file" help:"archive to write, JSON or YAML by extension, defaults to YAML on stdout"`
+}
+
+type RestoreCmd struct {
+	File   string `arg:"-f,--file,required" help:"archive to restore"`
+	DryRun bool   `arg:"--dry-run" help:"show what would be restored without making changes"`
+}
+
 type GetCmd struct {
 	Host *struct {
 		ID string `arg:"positional"`
 	} `arg:"subcommand:host"`
+	Rule *struct {
+		ID      string `arg:"positional"`
+		Include string `arg:"--include" help:"related resources to include, e.g. source_hosts"`
+	} `arg:"subcommand:rule"`
+}
+
+type LintCmd struct {
+	Snapshot string `arg:"-s,--snapshot" help:"archive written by export, instead of fetching from the account"`
+	MaxHops  int    `arg:"--max-hops" default:"1" help:"redirects allowed before a chain is reported"`
 }
 
 type ListCmd struct {
@@ -35,12 +60,27 @@ type ListCmd struct {
 	} `arg:"subcommand:rules"`
 }
 
+type PlanCmd struct {
+	File  string `arg:"-f,--file,required" help:"manifest of desired rules and host settings"`
+	Prune bool   `arg:"--prune" help:"delete rules that are not in the manifest"`
+}
+
+type ApplyCmd struct {
+	File  string `arg:"-f,--file,required" help:"manifest of desired rules and host settings"`
+	Prune bool   `arg:"--prune" help:"delete rules that are not in the manifest"`
+}
+
 type RemoveCmd struct {
 	Rule *struct {
 		ID string `arg:"positional"`
 	} `arg:"subcommand:rule"`
 }
 
+type TestURLCmd struct {
+	URL      string `arg:"positional,required"`
+	Snapshot string `arg:"-s,--snapshot,required" help:"archive written by export"`
+}
+
 type UpdateCmd struct {
 	Host *struct {
 		ID                      string             `arg:"positional"`
@@ -67,26 +107,69 @@ type UpdateCmd struct {
 	} `arg:"subcommand:rule"`
 }
 
+// ExitChanges is the exit code used by plan and apply when there are changes.
+const ExitChanges = 2
+
 var args struct {
-	APIKey    string     `arg:"env:EASYREDIR_API_KEY"`
-	APISecret string     `arg:"env:EASYREDIR_API_SECRET"`
-	Create    *CreateCmd `arg:"subcommand:create"`
-	Get       *GetCmd    `arg:"subcommand:get"`
-	List      *ListCmd   `arg:"subcommand:list"`
-	Remove    *RemoveCmd `arg:"subcommand:remove"`
-	Update    *UpdateCmd `arg:"subcommand:update"`
+	APIKey    string      `arg:"env:EASYREDIR_API_KEY"`
+	APISecret string      `arg:"env:EASYREDIR_API_SECRET"`
+	Debug     bool        `arg:"--debug" help:"log API requests and responses"`
+	Apply     *ApplyCmd   `arg:"subcommand:apply" help:"apply a manifest, exits 2 when changes were made"`
+	Create    *CreateCmd  `arg:"subcommand:create"`
+	Export    *ExportCmd  `arg:"subcommand:export" help:"back up all rules and hosts"`
+	Get       *GetCmd     `arg:"subcommand:get"`
+	Lint      *LintCmd    `arg:"subcommand:lint" help:"check rules for loops, chains and duplicate sources, exits 1 when problems are found"`
+	List      *ListCmd    `arg:"subcommand:list"`
+	Plan      *PlanCmd    `arg:"subcommand:plan" help:"show changes needed for a manifest or drift from it, exits 2 when there are changes"`
+	Remove    *RemoveCmd  `arg:"subcommand:remove"`
+	Restore   *RestoreCmd `arg:"subcommand:restore" help:"restore rules and hosts from a backup, exits 1 when anything could not be restored"`
+	TestURL   *TestURLCmd `arg:"subcommand:test-url" help:"show where a URL redirects to, offline from a snapshot"`
+	Update    *UpdateCmd  `arg:"subcommand:update"`
 }
 
 func main() {
 	log.SetFlags(0)
 	p := arg.MustParse(&args)
 
-	e := easyredir.New(
+	opts := []option.Option{
 		easyredir.WithAPIKey(args.APIKey),
 		easyredir.WithAPISecret(args.APISecret),
-	)
+	}
+
+	if args.Debug {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		}))
+		opts = append(opts, easyredir.WithLogger{Logger: logger})
+	}
+
+	e := easyredir.New(opts...)
 
 	switch {
+	case args.Apply != nil:
+		ctx := context.Background()
+
+		m, err := reconcile.LoadManifest(args.Apply.File)
+		if err != nil {
+			log.Fatalf("unable to load manifest: %v\n", err)
+		}
+
+		p, err := reconcile.BuildPlan(ctx, e.Client, m, args.Apply.Prune)
+		if err != nil {
+			log.Fatalf("unable to plan: %v\n", err)
+		}
+		fmt.Print(p)
+
+		res, err := reconcile.Apply(ctx, e.Client, p)
+		if err != nil {
+			fmt.Println(res)
+			log.Fatalf("unable to apply: %v\n", err)
+		}
+		if p.HasChanges() {
+			fmt.Println(res)
+			os.Exit(ExitChanges)
+		}
+
 	case args.Create != nil:
 		switch {
 		case args.Create.Rule != nil:
@@ -103,6 +186,21 @@ func main() {
 			fmt.Print(r)
 		}
 
+	case args.Export != nil:
+		a, err := backup.Export(context.Background(), e.Client)
+		if err != nil {
+			log.Fatalf("unable to export: %v\n", err)
+		}
+
+		if args.Export.File == "" {
+			err = a.Write(os.Stdout, backup.FormatYAML)
+		} else {
+			err = a.WriteFile(args.Export.File)
+		}
+		if err != nil {
+			log.Fatalf("unable to write archive: %v\n", err)
+		}
+
 	case args.Get != nil:
 		switch {
 		case args.Get.Host != nil:
@@ -111,6 +209,36 @@ func main() {
 				log.Fatalf("unable to get host: %v: %v\n", args.Get.Host.ID, err)
 			}
 			fmt.Print(h)
+
+		case args.Get.Rule != nil:
+			r, err := e.GetRule(args.Get.Rule.ID, easyredir.WithInclude(args.Get.Rule.Include))
+			if err != nil {
+				log.Fatalf("unable to get rule: %v: %v\n", args.Get.Rule.ID, err)
+			}
+			fmt.Print(r)
+		}
+
+	case args.Lint != nil:
+		var (
+			a   backup.Archive
+			err error
+		)
+		if args.Lint.Snapshot != "" {
+			a, err = backup.LoadArchive(args.Lint.Snapshot)
+		} else {
+			a, err = backup.Export(context.Background(), e.Client)
+		}
+		if err != nil {
+			log.Fatalf("unable to load rules: %v\n", err)
+		}
+
+		rs, hs := rule.Rules{Data: a.Rules}, host.Hosts{Data: a.Hosts}
+
+		fs := lint.Redirects(rs, hs, args.Lint.MaxHops)
+		fs = append(fs, lint.Duplicates(rs, hs)...)
+		fmt.Print(lint.Format(fs))
+		if len(fs) > 0 {
+			os.Exit(1)
 		}
 
 	case args.List != nil:
@@ -133,6 +261,21 @@ func main() {
 			log.Print(r)
 		}
 
+	case args.Plan != nil:
+		m, err := reconcile.LoadManifest(args.Plan.File)
+		if err != nil {
+			log.Fatalf("unable to load manifest: %v\n", err)
+		}
+
+		p, err := reconcile.BuildPlan(context.Background(), e.Client, m, args.Plan.Prune)
+		if err != nil {
+			log.Fatalf("unable to plan: %v\n", err)
+		}
+		fmt.Print(p)
+		if p.HasChanges() {
+			os.Exit(ExitChanges)
+		}
+
 	case args.Remove != nil:
 		switch {
 		case args.Remove.Rule != nil:
@@ -143,6 +286,35 @@ func main() {
 			log.Printf("Result of remove rule for %v: %v\n", args.Remove.Rule.ID, res)
 		}
 
+	case args.Restore != nil:
+		a, err := backup.LoadArchive(args.Restore.File)
+		if err != nil {
+			log.Fatalf("unable to load archive: %v\n", err)
+		}
+
+		rep, err := backup.Restore(context.Background(), e.Client, a, args.Restore.DryRun)
+		if err != nil {
+			log.Fatalf("unable to restore: %v\n", err)
+		}
+		fmt.Print(rep)
+		if !rep.OK() {
+			os.Exit(1)
+		}
+
+	case args.TestURL != nil:
+		a, err := backup.LoadArchive(args.TestURL.Snapshot)
+		if err != nil {
+			log.Fatalf("unable to load snapshot: %v\n", err)
+		}
+
+		sim := simulator.New(rule.Rules{Data: a.Rules}, host.Hosts{Data: a.Hosts})
+
+		res, err := sim.Resolve(args.TestURL.URL)
+		if err != nil {
+			log.Fatalf("unable to resolve url: %v\n", err)
+		}
+		fmt.Print(res)
+
 	case args.Update != nil:
 		switch {
 		case args.Update.Host != nil:
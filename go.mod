@@ -1,6 +1,6 @@
 module github.com/mikelorant/easyredir
 
-go 1.18
+go 1.21
 
 require (
 	github.com/MakeNowJust/heredoc v1.0.0
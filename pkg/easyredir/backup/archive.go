@@ -0,0 +1,111 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+// SchemaVersion is the version of the archive format written by Export.
+// Archives with a newer version are rejected.
+const SchemaVersion = 1
+
+var ErrSchemaVersion = errors.New("unsupported schema version")
+
+// Archive is a copy of every rule and host in an account.
+type Archive struct {
+	SchemaVersion int         `json:"schema_version"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Rules         []rule.Data `json:"rules"`
+	Hosts         []host.Data `json:"hosts"`
+}
+
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// FormatFromPath picks the format from the file extension, defaulting to
+// YAML.
+func FormatFromPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		return FormatJSON
+	}
+
+	return FormatYAML
+}
+
+func (a Archive) Write(w io.Writer, f Format) error {
+	var (
+		b   []byte
+		err error
+	)
+
+	switch f {
+	case FormatJSON:
+		b, err = json.MarshalIndent(a, "", "  ")
+		b = append(b, '\n')
+	case FormatYAML:
+		b, err = yaml.Marshal(a)
+	default:
+		return fmt.Errorf("unknown format: %v", f)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to encode archive: %w", err)
+	}
+
+	if _, err := w.Write(b); err != nil {
+		return fmt.Errorf("unable to write archive: %w", err)
+	}
+
+	return nil
+}
+
+func (a Archive) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("unable to create archive: %w", err)
+	}
+
+	if err := a.Write(f, FormatFromPath(path)); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// ReadArchive decodes an archive in either format and checks its schema
+// version.
+func ReadArchive(r io.Reader) (a Archive, err error) {
+	if err := yaml.NewDecoder(r).Decode(&a); err != nil {
+		return a, fmt.Errorf("unable to decode archive: %w", err)
+	}
+
+	if a.SchemaVersion < 1 || a.SchemaVersion > SchemaVersion {
+		return a, fmt.Errorf("%w: %v", ErrSchemaVersion, a.SchemaVersion)
+	}
+
+	return a, nil
+}
+
+func LoadArchive(path string) (a Archive, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return a, fmt.Errorf("unable to open archive: %w", err)
+	}
+	defer f.Close()
+
+	return ReadArchive(f)
+}
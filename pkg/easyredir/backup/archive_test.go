@@ -0,0 +1,151 @@
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func archive() Archive {
+	return Archive{
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC),
+		Rules: []rule.Data{
+			{
+				ID:   "rule-1",
+				Type: "rule",
+				Attributes: rule.Attributes{
+					ForwardParams: ptr.Bool(true),
+					ForwardPath:   ptr.Bool(false),
+					ResponseType:  ref(rule.ResponseFound),
+					SourceURLs:    []string{"abc.com/1"},
+					TargetURL:     ptr.String("https://otherdomain.com"),
+				},
+				Relationships: rule.Relationships{
+					SourceHosts: rule.SourceHosts{
+						Data: []rule.SourceHostData{
+							{ID: "host-1", Type: "host"},
+						},
+					},
+				},
+			},
+			{
+				ID:   "rule-2",
+				Type: "rule",
+				Attributes: rule.Attributes{
+					SourceURLs: []string{"xyz.com/1"},
+					TargetURL:  ptr.String("https://otherdomain.com"),
+				},
+			},
+		},
+		Hosts: []host.Data{
+			{
+				ID:   "host-1",
+				Type: "host",
+				Attributes: host.Attributes{
+					Name: "abc.com",
+					NotFoundAction: host.NotFoundAction{
+						Custom404BodyPresent: ptr.Bool(true),
+						ResponseCode:         ref(host.ResponseCodeFound),
+					},
+					Security: host.Security{
+						HTTPSUpgrade: ptr.Bool(true),
+					},
+				},
+			},
+			{
+				ID:   "host-2",
+				Type: "host",
+				Attributes: host.Attributes{
+					Name: "xyz.com",
+					NotFoundAction: host.NotFoundAction{
+						Custom404BodyPresent: ptr.Bool(true),
+					},
+				},
+			},
+			{
+				ID:   "host-3",
+				Type: "host",
+				Attributes: host.Attributes{
+					Name: "zzz.com",
+				},
+			},
+		},
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		give Format
+	}{
+		{name: "json", give: FormatJSON},
+		{name: "yaml", give: FormatYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+
+			err := archive().Write(&b, tt.give)
+			assert.Nil(t, err)
+
+			got, err := ReadArchive(&b)
+			assert.Nil(t, err)
+			td.Cmp(t, got, archive())
+		})
+	}
+}
+
+func TestReadArchive(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{
+			name: "missing_version",
+			give: `{"rules": []}`,
+			want: "unsupported schema version: 0",
+		},
+		{
+			name: "newer_version",
+			give: "schema_version: 2\n",
+			want: "unsupported schema version: 2",
+		},
+		{
+			name: "malformed",
+			give: "schema_version: [",
+			want: "unable to decode archive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadArchive(strings.NewReader(tt.give))
+			assert.NotNil(t, err)
+			td.CmpContains(t, err, tt.want)
+			if tt.name != "malformed" {
+				td.CmpTrue(t, errors.Is(err, ErrSchemaVersion))
+			}
+		})
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	td.Cmp(t, FormatFromPath("backup.JSON"), FormatJSON)
+	td.Cmp(t, FormatFromPath("backup.yaml"), FormatYAML)
+	td.Cmp(t, FormatFromPath("backup"), FormatYAML)
+}
+
+func ref[T any](x T) *T {
+	return &x
+}
@@ -0,0 +1,46 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+type ClientAPI interface {
+	SendRequest(path, method string, body io.Reader) (io.ReadCloser, error)
+}
+
+// Export reads every rule and host. Each host is fetched individually so that
+// its full settings are kept, apart from a custom 404 body which the API does
+// not return.
+func Export(ctx context.Context, cl ClientAPI) (a Archive, err error) {
+	a = Archive{
+		SchemaVersion: SchemaVersion,
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+
+	rs, err := rule.ListRulesPaginatorWithContext(ctx, cl)
+	if err != nil {
+		return a, fmt.Errorf("unable to list rules: %w", err)
+	}
+	a.Rules = rs.Data
+
+	hs, err := host.ListHostsPaginatorWithContext(ctx, cl)
+	if err != nil {
+		return a, fmt.Errorf("unable to list hosts: %w", err)
+	}
+
+	for _, d := range hs.Data {
+		h, err := host.GetHostWithContext(ctx, cl, d.ID)
+		if err != nil {
+			return a, fmt.Errorf("unable to get host: %v: %w", d.ID, err)
+		}
+		a.Hosts = append(a.Hosts, h.Data)
+	}
+
+	return a, nil
+}
@@ -0,0 +1,209 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/sourceurl"
+	"github.com/mikelorant/easyredir/pkg/easyredir/reconcile"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+// Report describes a restore. The plan holds every change that was attempted,
+// or would be with a dry run.
+type Report struct {
+	DryRun   bool
+	Plan     reconcile.Plan
+	Pending  []string
+	Failures []Failure
+}
+
+// Failure is a rule or host that could not be restored.
+type Failure struct {
+	Kind string
+	Name string
+	Err  error
+}
+
+// ErrCustom404Body is reported for hosts that had a custom 404 body which the
+// host no longer has. The API does not return the body so it is not in the
+// archive.
+var ErrCustom404Body = errors.New("custom 404 body was not exported and must be set again")
+
+// Restore recreates the rules in the archive and then reapplies the host
+// settings. Existing rules are matched on their source URLs and updated to
+// match the archive, including dropping source URLs it does not have. Source
+// URLs in the archive are also taken from any other rule, which is deleted if
+// it has none left. A dry run shows these changes in the plan. Rules sharing
+// no source URLs with the archive are left alone. Hosts only exist once a rule
+// uses them, so with a dry run any host that a restored rule would create is
+// reported as pending.
+func Restore(ctx context.Context, cl ClientAPI, a Archive, dryRun bool) (rep Report, err error) {
+	rep.DryRun = dryRun
+
+	rp, err := reconcile.PlanRules(ctx, cl, reconcile.Manifest{Rules: rules(a.Rules)}, false)
+	if err != nil {
+		return rep, err
+	}
+	rep.Plan.Rules = rp.Rules
+
+	if !dryRun {
+		for _, c := range rp.Rules {
+			if err := c.Apply(ctx, cl); err != nil {
+				rep.Failures = append(rep.Failures, Failure{Kind: "rule", Name: strings.Join(c.SourceURLs, ", "), Err: err})
+			}
+		}
+	}
+
+	hs, err := host.ListHostsPaginatorWithContext(ctx, cl)
+	if err != nil {
+		return rep, fmt.Errorf("unable to list hosts: %w", err)
+	}
+
+	present := make(map[string]string)
+	for _, d := range hs.Data {
+		present[strings.ToLower(d.Attributes.Name)] = d.ID
+	}
+
+	sources := sourceHosts(a.Rules)
+
+	m := reconcile.Manifest{Hosts: make(map[string]reconcile.Host)}
+	for _, d := range a.Hosts {
+		name := d.Attributes.Name
+		id, ok := present[strings.ToLower(name)]
+
+		switch {
+		case ok:
+			m.Hosts[name] = settings(d.Attributes)
+
+			if hasCustom404Body(d.Attributes) {
+				h, err := host.GetHostWithContext(ctx, cl, id)
+				if err != nil {
+					return rep, fmt.Errorf("unable to get host: %v: %w", name, err)
+				}
+				if !hasCustom404Body(h.Data.Attributes) {
+					rep.Failures = append(rep.Failures, Failure{Kind: "host", Name: name, Err: ErrCustom404Body})
+				}
+			}
+		case dryRun && sources[strings.ToLower(name)]:
+			rep.Pending = append(rep.Pending, name)
+
+			if hasCustom404Body(d.Attributes) {
+				rep.Failures = append(rep.Failures, Failure{Kind: "host", Name: name, Err: ErrCustom404Body})
+			}
+		default:
+			rep.Failures = append(rep.Failures, Failure{Kind: "host", Name: name, Err: fmt.Errorf("host not found")})
+		}
+	}
+
+	hp, err := reconcile.PlanHosts(ctx, cl, m)
+	if err != nil {
+		return rep, err
+	}
+	rep.Plan.Hosts = hp.Hosts
+
+	if !dryRun {
+		for _, c := range hp.Hosts {
+			if err := c.Apply(ctx, cl); err != nil {
+				rep.Failures = append(rep.Failures, Failure{Kind: "host", Name: c.Name, Err: err})
+			}
+		}
+	}
+
+	return rep, nil
+}
+
+func rules(ds []rule.Data) []reconcile.Rule {
+	var rs []reconcile.Rule
+
+	for _, d := range ds {
+		a := d.Attributes
+
+		r := reconcile.Rule{
+			SourceURLs: a.SourceURLs,
+		}
+		if a.TargetURL != nil {
+			r.TargetURL = *a.TargetURL
+		}
+		if a.ResponseType != nil {
+			r.ResponseType = *a.ResponseType
+		}
+		if a.ForwardParams != nil {
+			r.ForwardParams = *a.ForwardParams
+		}
+		if a.ForwardPath != nil {
+			r.ForwardPath = *a.ForwardPath
+		}
+
+		rs = append(rs, r)
+	}
+
+	return rs
+}
+
+func settings(a host.Attributes) reconcile.Host {
+	h := reconcile.Host{
+		MatchOptions:   a.MatchOptions,
+		NotFoundAction: a.NotFoundAction,
+		Security:       a.Security,
+	}
+
+	// Read only.
+	h.NotFoundAction.Custom404BodyPresent = nil
+
+	return h
+}
+
+func hasCustom404Body(a host.Attributes) bool {
+	p := a.NotFoundAction.Custom404BodyPresent
+	return p != nil && *p
+}
+
+// sourceHosts returns the lower case host names used by the rules.
+func sourceHosts(ds []rule.Data) map[string]bool {
+	hosts := make(map[string]bool)
+
+	for _, d := range ds {
+		for _, s := range d.Attributes.SourceURLs {
+			if name := sourceurl.Hostname(s); name != "" {
+				hosts[name] = true
+			}
+		}
+	}
+
+	return hosts
+}
+
+func (r Report) OK() bool {
+	return len(r.Failures) == 0
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+
+	if r.DryRun {
+		fmt.Fprintln(&b, "Dry run, no changes were made.")
+	}
+
+	fmt.Fprint(&b, r.Plan)
+
+	for _, name := range r.Pending {
+		fmt.Fprintf(&b, "Host %v will be restored once its rules exist.\n", name)
+	}
+
+	if len(r.Failures) > 0 {
+		fmt.Fprintln(&b, "\nUnable to restore:")
+		for _, f := range r.Failures {
+			fmt.Fprintf(&b, "  %v\n", f)
+		}
+	}
+
+	return b.String()
+}
+
+func (f Failure) String() string {
+	return fmt.Sprintf("%v %v: %v", f.Kind, f.Name, f.Err)
+}
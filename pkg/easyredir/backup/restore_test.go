@@ -0,0 +1,244 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/stretchr/testify/assert"
+)
+
+type WithBaseURL string
+
+func (u WithBaseURL) Apply(o *option.Options) {
+	o.BaseURL = string(u)
+}
+
+func account(requests *[]string, custom404Body bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		*requests = append(*requests, strings.TrimSpace(fmt.Sprintf("%v %v %s", req.Method, req.URL.Path, body)))
+
+		switch {
+		case req.Method == http.MethodGet && req.URL.Path == "/rules":
+			w.Write([]byte(`{"data": []}`))
+		case req.Method == http.MethodPost && strings.Contains(string(body), "xyz.com"):
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"type": "invalid_request_error", "message": "Invalid Request"}`))
+		case req.Method == http.MethodPost:
+			w.Write([]byte(`{"data": {"id": "rule-9", "type": "rule"}}`))
+		case req.URL.Path == "/hosts":
+			w.Write([]byte(`{"data": [{"id": "host-9", "type": "host", "attributes": {"name": "abc.com"}}]}`))
+		default:
+			fmt.Fprintf(w, `{"data": {"id": "host-9", "type": "host", "attributes": {"name": "abc.com", "not_found_action": {"custom_404_body_present": %v, "response_code": 302}}}}`, custom404Body)
+		}
+	})
+
+	return mux
+}
+
+func TestRestore(t *testing.T) {
+	type Want struct {
+		report   string
+		requests []string
+	}
+
+	tests := []struct {
+		name          string
+		dryRun        bool
+		custom404Body bool
+		want          Want
+	}{
+		{
+			name:   "dry_run",
+			dryRun: true,
+			want: Want{
+				report: heredoc.Doc(`
+					Dry run, no changes were made.
+					+ create rule abc.com/1
+					    source_urls: [] => [abc.com/1]
+					    target_url: "" => "https://otherdomain.com"
+					    response_type: moved_permanently => found
+					    forward_params: false => true
+					+ create rule xyz.com/1
+					    source_urls: [] => [xyz.com/1]
+					    target_url: "" => "https://otherdomain.com"
+					~ update host abc.com (host-9)
+					    security.https_upgrade: (unset) => true
+
+					Plan: 2 to create, 1 to update, 0 to delete.
+					Host xyz.com will be restored once its rules exist.
+
+					Unable to restore:
+					  host abc.com: custom 404 body was not exported and must be set again
+					  host xyz.com: custom 404 body was not exported and must be set again
+					  host zzz.com: host not found
+				`),
+				requests: []string{
+					"GET /rules",
+					"GET /hosts",
+					"GET /hosts/host-9",
+					"GET /hosts",
+					"GET /hosts/host-9",
+				},
+			},
+		},
+		{
+			name:          "custom_404_body_present",
+			dryRun:        true,
+			custom404Body: true,
+			want: Want{
+				report: heredoc.Doc(`
+					Dry run, no changes were made.
+					+ create rule abc.com/1
+					    source_urls: [] => [abc.com/1]
+					    target_url: "" => "https://otherdomain.com"
+					    response_type: moved_permanently => found
+					    forward_params: false => true
+					+ create rule xyz.com/1
+					    source_urls: [] => [xyz.com/1]
+					    target_url: "" => "https://otherdomain.com"
+					~ update host abc.com (host-9)
+					    security.https_upgrade: (unset) => true
+
+					Plan: 2 to create, 1 to update, 0 to delete.
+					Host xyz.com will be restored once its rules exist.
+
+					Unable to restore:
+					  host xyz.com: custom 404 body was not exported and must be set again
+					  host zzz.com: host not found
+				`),
+				requests: []string{
+					"GET /rules",
+					"GET /hosts",
+					"GET /hosts/host-9",
+					"GET /hosts",
+					"GET /hosts/host-9",
+				},
+			},
+		},
+		{
+			name: "restore",
+			want: Want{
+				report: heredoc.Doc(`
+					+ create rule abc.com/1
+					    source_urls: [] => [abc.com/1]
+					    target_url: "" => "https://otherdomain.com"
+					    response_type: moved_permanently => found
+					    forward_params: false => true
+					+ create rule xyz.com/1
+					    source_urls: [] => [xyz.com/1]
+					    target_url: "" => "https://otherdomain.com"
+					~ update host abc.com (host-9)
+					    security.https_upgrade: (unset) => true
+
+					Plan: 2 to create, 1 to update, 0 to delete.
+
+					Unable to restore:
+					  rule xyz.com/1: unable to create rule: xyz.com/1: unable to send request: invalid_request_error: Invalid Request
+					  host abc.com: custom 404 body was not exported and must be set again
+					  host xyz.com: host not found
+					  host zzz.com: host not found
+				`),
+				requests: []string{
+					"GET /rules",
+					`POST /rules {"forward_params":true,"forward_path":false,"response_type":"found","source_urls":["abc.com/1"],"target_url":"https://otherdomain.com"}`,
+					`POST /rules {"forward_params":false,"forward_path":false,"response_type":"moved_permanently","source_urls":["xyz.com/1"],"target_url":"https://otherdomain.com"}`,
+					"GET /hosts",
+					"GET /hosts/host-9",
+					"GET /hosts",
+					"GET /hosts/host-9",
+					`PATCH /hosts/host-9 {"match_options":{},"not_found_action":{},"security":{"https_upgrade":true},"required_dns_entries":{"recommended":{}}}`,
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+
+			server := httptest.NewServer(account(&requests, tt.custom404Body))
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			rep, err := Restore(context.Background(), cl, archive(), tt.dryRun)
+			assert.Nil(t, err)
+			td.Cmp(t, rep.String(), tt.want.report)
+			td.Cmp(t, rep.OK(), false)
+			td.Cmp(t, requests, tt.want.requests)
+		})
+	}
+}
+
+func TestRestoreClaimedSources(t *testing.T) {
+	var requests []string
+
+	next := account(&requests, true)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && req.URL.Path == "/rules" {
+			requests = append(requests, "GET /rules")
+			w.Write([]byte(`{"data": [
+				{"id": "rule-5", "type": "rule", "attributes": {"source_urls": ["abc.com/1", "abc.com/9"], "target_url": "https://otherdomain.com"}},
+				{"id": "rule-6", "type": "rule", "attributes": {"source_urls": ["abc.com/8"], "target_url": "https://otherdomain.com"}}
+			]}`))
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	rep, err := Restore(context.Background(), cl, archive(), true)
+	assert.Nil(t, err)
+	td.Cmp(t, rep.String(), heredoc.Doc(`
+		Dry run, no changes were made.
+		~ update rule rule-5 (abc.com/1, abc.com/9)
+		    source_urls: [abc.com/1 abc.com/9] => [abc.com/1]
+		    response_type: moved_permanently => found
+		    forward_params: false => true
+		+ create rule xyz.com/1
+		    source_urls: [] => [xyz.com/1]
+		    target_url: "" => "https://otherdomain.com"
+		~ update host abc.com (host-9)
+		    security.https_upgrade: (unset) => true
+
+		Plan: 1 to create, 2 to update, 0 to delete.
+		Host xyz.com will be restored once its rules exist.
+
+		Unable to restore:
+		  host xyz.com: custom 404 body was not exported and must be set again
+		  host zzz.com: host not found
+	`))
+}
+
+func TestExport(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(account(&requests, false))
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	got, err := Export(context.Background(), cl)
+	assert.Nil(t, err)
+	td.Cmp(t, got.SchemaVersion, SchemaVersion)
+	td.Cmp(t, len(got.Rules), 0)
+	td.Cmp(t, len(got.Hosts), 1)
+	td.Cmp(t, got.Hosts[0].Attributes.NotFoundAction.ResponseCode, td.Ptr(host.ResponseCodeFound))
+	td.Cmp(t, requests, []string{"GET /rules", "GET /hosts", "GET /hosts/host-9"})
+}
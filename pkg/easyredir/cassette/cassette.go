@@ -0,0 +1,135 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Version is the cassette format written by the recorder.
+const Version = 1
+
+// Cassette is a sequence of recorded request and response pairs.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request holds the path and query but not the host, so that a cassette can
+// be replayed against any base URL.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+func Load(path string) (c Cassette, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return c, fmt.Errorf("unable to read cassette: %w", err)
+	}
+
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("unable to decode cassette: %v: %w", path, err)
+	}
+
+	if c.Version != Version {
+		return c, fmt.Errorf("unsupported cassette version: %v", c.Version)
+	}
+
+	return c, nil
+}
+
+func (c Cassette) Save(path string) error {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("unable to encode cassette: %w", err)
+	}
+
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return fmt.Errorf("unable to write cassette: %w", err)
+	}
+
+	return nil
+}
+
+// key identifies a request for matching. The query is sorted and a JSON body
+// is compacted with sorted keys, so neither parameter order nor formatting
+// affect a match.
+func key(method, rawURL, body string) string {
+	return method + " " + normalizeURL(rawURL) + " " + normalizeBody(body)
+}
+
+func normalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	if u.RawQuery == "" {
+		return u.Path
+	}
+
+	return u.Path + "?" + u.Query().Encode()
+}
+
+func normalizeBody(body string) string {
+	if strings.TrimSpace(body) == "" {
+		return ""
+	}
+
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return body
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+
+	return string(b)
+}
+
+// readBody returns the request body while leaving it readable.
+func readBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close()
+
+		b, err := io.ReadAll(rc)
+		return string(b), err
+	}
+
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+
+	return string(b), err
+}
@@ -0,0 +1,146 @@
+package cassette
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir"
+	"github.com/mikelorant/easyredir/pkg/easyredir/easyredirtest"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	type Args struct {
+		method string
+		url    string
+		body   string
+	}
+
+	tests := []struct {
+		name string
+		give Args
+		want string
+	}{
+		{
+			name: "path",
+			give: Args{method: "GET", url: "/v1/rules"},
+			want: "GET /v1/rules ",
+		},
+		{
+			name: "query_order",
+			give: Args{method: "GET", url: "/v1/rules?tq=b&sq=a"},
+			want: "GET /v1/rules?sq=a&tq=b ",
+		},
+		{
+			name: "json_body",
+			give: Args{method: "POST", url: "/v1/rules", body: "{\n  \"target_url\": \"x\",\n  \"source_urls\": [\"a\"]\n}"},
+			want: `POST /v1/rules {"source_urls":["a"],"target_url":"x"}`,
+		},
+		{
+			name: "other_body",
+			give: Args{method: "POST", url: "/v1/rules", body: "not json"},
+			want: "POST /v1/rules not json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, key(tt.give.method, tt.give.url, tt.give.body), tt.want)
+		})
+	}
+}
+
+func session(e *easyredir.Easyredir) (rule.Rules, error) {
+	r, err := e.CreateRule(rule.Attributes{
+		SourceURLs: []string{"abc.com"},
+		TargetURL:  ptr.String("https://otherdomain.com"),
+	})
+	if err != nil {
+		return rule.Rules{}, err
+	}
+
+	if _, err := e.UpdateRule(r.Data.ID, rule.Attributes{TargetURL: ptr.String("https://newdomain.com")}); err != nil {
+		return rule.Rules{}, err
+	}
+
+	return e.ListRules(easyredir.WithLimit(10))
+}
+
+func TestRecordReplay(t *testing.T) {
+	s := easyredirtest.NewServer()
+	defer s.Close()
+
+	rec := NewRecorder(nil)
+	e := easyredir.New(append(s.Options(), easyredir.WithHTTPClient{Client: rec})...)
+
+	recorded, err := session(e)
+	assert.Nil(t, err)
+
+	path := filepath.Join(t.TempDir(), "session.yaml")
+	assert.Nil(t, rec.Save(path))
+
+	b, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	td.CmpNot(t, string(b), td.Contains(easyredirtest.APISecret))
+	td.CmpNot(t, string(b), td.Contains("Basic "))
+	td.Cmp(t, strings.Count(string(b), "Idempotency-Key"), 2)
+	td.Cmp(t, len(rec.Cassette().Interactions), 3)
+
+	c, err := Load(path)
+	assert.Nil(t, err)
+
+	rep := NewReplayer(c)
+	e = easyredir.New(
+		easyredir.WithBaseURL("http://replay.invalid"),
+		easyredir.WithHTTPClient{Client: rep},
+	)
+
+	replayed, err := session(e)
+	assert.Nil(t, err)
+	td.Cmp(t, replayed, recorded)
+	td.Cmp(t, len(rep.Unused()), 0)
+
+	_, err = e.ListRules()
+	td.CmpTrue(t, errors.Is(err, ErrNoInteraction))
+	td.CmpContains(t, err, "GET /rules, recorded for this path: GET /rules?limit=10 (already used)")
+}
+
+func TestReplayNoMatch(t *testing.T) {
+	c := Cassette{
+		Version: Version,
+		Interactions: []Interaction{
+			{
+				Request:  Request{Method: http.MethodPost, URL: "/rules", Body: `{"source_urls":["abc.com"],"target_url":"x"}`},
+				Response: Response{StatusCode: http.StatusCreated, Body: `{"data": {"id": "rule-1"}}`},
+			},
+		},
+	}
+
+	e := easyredir.New(
+		easyredir.WithBaseURL("http://replay.invalid"),
+		easyredir.WithHTTPClient{Client: NewReplayer(c)},
+	)
+
+	_, err := e.CreateRule(rule.Attributes{SourceURLs: []string{"abc.com"}, TargetURL: ptr.String("y")})
+	td.CmpTrue(t, errors.Is(err, ErrNoInteraction))
+	td.CmpContains(t, err, `POST /rules with body {"source_urls":["abc.com"],"target_url":"y"}, recorded for this path: POST /rules with body {"source_urls":["abc.com"],"target_url":"x"}`)
+
+	r, err := e.CreateRule(rule.Attributes{TargetURL: ptr.String("x"), SourceURLs: []string{"abc.com"}})
+	assert.Nil(t, err)
+	td.Cmp(t, r.Data.ID, "rule-1")
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.yaml")
+	assert.Nil(t, os.WriteFile(path, []byte("version: 9\n"), 0o644))
+
+	_, err := Load(path)
+	td.CmpContains(t, err, "unsupported cassette version: 9")
+}
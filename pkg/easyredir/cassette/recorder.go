@@ -0,0 +1,105 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+)
+
+// ScrubbedHeaders are replaced before an interaction is stored. Auth headers
+// hold the credentials and idempotency keys change on every run.
+var ScrubbedHeaders = []string{
+	"Authorization",
+	"Idempotency-Key",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// Recorder is a Doer that passes requests to the wrapped Doer and records
+// each request and response.
+type Recorder struct {
+	doer option.Doer
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+func NewRecorder(d option.Doer) *Recorder {
+	if d == nil {
+		d = http.DefaultClient
+	}
+
+	return &Recorder{
+		doer:     d,
+		cassette: Cassette{Version: Version},
+	}
+}
+
+func (r *Recorder) Do(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read request body: %w", err)
+	}
+
+	resp, err := r.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.RequestURI(),
+			Header: scrub(req.Header),
+			Body:   reqBody,
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     scrub(resp.Header),
+			Body:       string(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+// Cassette returns a copy of what has been recorded so far.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.cassette
+	c.Interactions = append([]Interaction(nil), c.Interactions...)
+
+	return c
+}
+
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+func scrub(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range ScrubbedHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, client.Redacted)
+		}
+	}
+
+	return h
+}
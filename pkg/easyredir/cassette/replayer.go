@@ -0,0 +1,114 @@
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// Replayer is a Doer that answers requests from a cassette without touching
+// the network. Interactions are matched on method, path and body and each is
+// used once, in the order they were recorded.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+func NewReplayer(c Cassette) *Replayer {
+	return &Replayer{
+		cassette: c,
+		used:     make([]bool, len(c.Interactions)),
+	}
+}
+
+func (r *Replayer) Do(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read request body: %w", err)
+	}
+
+	want := key(req.Method, req.URL.RequestURI(), body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || key(in.Request.Method, in.Request.URL, in.Request.Body) != want {
+			continue
+		}
+		r.used[i] = true
+
+		return response(req, in.Response), nil
+	}
+
+	return nil, r.noMatch(req, body)
+}
+
+// Unused returns the interactions that were never replayed.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var in []Interaction
+	for i, u := range r.used {
+		if !u {
+			in = append(in, r.cassette.Interactions[i])
+		}
+	}
+
+	return in
+}
+
+// noMatch describes the request and lists the recorded requests with the same
+// method and path to show why nothing matched.
+func (r *Replayer) noMatch(req *http.Request, body string) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%v %v", req.Method, req.URL.RequestURI())
+	if body != "" {
+		fmt.Fprintf(&b, " with body %v", normalizeBody(body))
+	}
+
+	var candidates []string
+	for i, in := range r.cassette.Interactions {
+		if u, err := url.Parse(in.Request.URL); err != nil || in.Request.Method != req.Method || u.Path != req.URL.Path {
+			continue
+		}
+
+		c := fmt.Sprintf("%v %v", in.Request.Method, in.Request.URL)
+		if in.Request.Body != "" {
+			c += " with body " + normalizeBody(in.Request.Body)
+		}
+		if r.used[i] {
+			c += " (already used)"
+		}
+		candidates = append(candidates, c)
+	}
+
+	if len(candidates) > 0 {
+		fmt.Fprintf(&b, ", recorded for this path: %v", strings.Join(candidates, "; "))
+	}
+
+	return fmt.Errorf("%w: %v", ErrNoInteraction, b.String())
+}
+
+func response(req *http.Request, res Response) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %v", res.StatusCode, http.StatusText(res.StatusCode)),
+		StatusCode:    res.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        res.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(res.Body)),
+		ContentLength: int64(len(res.Body)),
+		Request:       req,
+	}
+}
@@ -1,7 +1,8 @@
 package client
 
 import (
-	"errors"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,8 +12,6 @@ import (
 	"github.com/mikelorant/easyredir/pkg/jsonutil"
 )
 
-var ErrUnknown = errors.New("unknown error")
-
 func New(opts ...option.Option) *Client {
 	o := &option.Options{}
 
@@ -23,47 +22,145 @@ func New(opts ...option.Option) *Client {
 	return &Client{
 		HTTPClient: buildHTTPClient(o),
 		Config:     buildConfig(o),
+		Limiter:    o.Limiter,
 	}
 }
 
 func (cl *Client) SendRequest(path, method string, body io.Reader) (io.ReadCloser, error) {
+	return cl.SendRequestWithContext(context.Background(), path, method, body)
+}
+
+func (cl *Client) SendRequestWithContext(ctx context.Context, path, method string, body io.Reader) (io.ReadCloser, error) {
 	url := fmt.Sprintf("%v%v", cl.Config.BaseURL, path)
 
-	req, err := http.NewRequest(method, url, body)
-	if err != nil {
-		return nil, fmt.Errorf("unable to create a new request: %w", err)
+	var payload []byte
+	if body != nil {
+		b, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read request body: %w", err)
+		}
+		payload = b
+	}
+
+	var idempotencyKey string
+	if method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch {
+		idempotencyKey = uuid.NewString()
 	}
 
-	req.SetBasicAuth(cl.Config.APIKey, cl.Config.APISecret)
-	req.Header.Set("Content-Type", ResourceType)
-	req.Header.Set("Accept", ResourceType)
+	for attempt := 1; ; attempt++ {
+		if cl.Limiter != nil {
+			if err := cl.Limiter.Wait(ctx); err != nil {
+				return nil, fmt.Errorf("unable to wait for rate limiter: %w", err)
+			}
+		}
+
+		req, err := newRequest(ctx, url, method, payload)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create a new request: %w", err)
+		}
+
+		req.SetBasicAuth(cl.Config.APIKey, cl.Config.APISecret)
+		req.Header.Set("Content-Type", ResourceType)
+		req.Header.Set("Accept", ResourceType)
+
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+
+		resp, err := cl.HTTPClient.Do(req)
+		if err != nil {
+			if ctx.Err() == nil && cl.Config.Retry.canRetry(attempt) {
+				if err := sleep(ctx, cl.Config.Retry.backoff(attempt)); err != nil {
+					return nil, fmt.Errorf("unable to retry request: %w", err)
+				}
+				continue
+			}
+			return nil, fmt.Errorf("unable to do request: %w", err)
+		}
+
+		rl, present := parseRateLimit(resp.Header)
+		if present.any() {
+			cl.setRateLimit(rl)
+			if cl.Limiter != nil {
+				remaining := rl.Remaining
+				if !present.remaining {
+					remaining = -1
+				}
+				cl.Limiter.Update(rl.Limit, remaining, rl.Reset)
+			}
+		}
+
+		if isRetryable(resp.StatusCode) && cl.Config.Retry.canRetry(attempt) {
+			delay := cl.Config.Retry.backoff(attempt)
+			if resp.StatusCode == http.StatusTooManyRequests && !rl.Reset.IsZero() {
+				delay = rl.RetryAfter()
+			}
+
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			if err := sleep(ctx, delay); err != nil {
+				return nil, fmt.Errorf("unable to retry request: %w", err)
+			}
+			continue
+		}
 
-	if req.Method == "POST" || req.Method == "PUT" || req.Method == "PATCH" {
-		req.Header.Set("Idempotency-Key", uuid.NewString())
+		return handleResponse(resp)
 	}
+}
+
+func newRequest(ctx context.Context, url, method string, payload []byte) (*http.Request, error) {
+	if payload == nil {
+		return http.NewRequestWithContext(ctx, method, url, nil)
+	}
+
+	return http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+}
 
-	resp, err := cl.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("unable to do request: %w", err)
+func handleResponse(resp *http.Response) (io.ReadCloser, error) {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusBadRequest {
+		return resp.Body, nil
 	}
 
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBodySize))
+	resp.Body.Close()
+
+	respErr := newResponseError(resp, b)
+
 	if resp.StatusCode == http.StatusTooManyRequests {
+		rl, _ := parseRateLimit(resp.Header)
 		return nil, &RateLimitError{
-			Limit:     resp.Header.Get("X-Ratelimit-Limit"),
-			Remaining: resp.Header.Get("X-Ratelimit-Remaining"),
-			Reset:     resp.Header.Get("X-Ratelimit-Reset"),
+			RateLimit: rl,
+			Response:  respErr,
 		}
 	}
 
-	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
-		apiErr := APIErrors{}
-		if err := jsonutil.DecodeJSON(resp.Body, &apiErr); err == nil {
-			return nil, apiErr
-		}
-		return nil, fmt.Errorf("%w: status code: %d", ErrUnknown, resp.StatusCode)
+	apiErr := APIErrors{}
+	if err := jsonutil.DecodeJSON(io.NopCloser(bytes.NewReader(b)), &apiErr); err == nil {
+		apiErr.StatusCode = resp.StatusCode
+		apiErr.Response = respErr
+		return nil, apiErr
 	}
 
-	return resp.Body, nil
+	kind := statusKind(resp.StatusCode)
+	if kind == nil {
+		kind = ErrUnknown
+	}
+
+	return nil, fmt.Errorf("%w: %w", kind, respErr)
+}
+
+func newResponseError(resp *http.Response, body []byte) *ResponseError {
+	if len(body) > MaxErrorBodySize {
+		body = body[:MaxErrorBodySize]
+	}
+
+	return &ResponseError{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       string(body),
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
 }
 
 func buildConfig(opts *option.Options) *Config {
@@ -83,13 +180,22 @@ func buildConfig(opts *option.Options) *Config {
 		cfg.APISecret = opts.APISecret
 	}
 
+	cfg.Retry = buildRetry(opts.Retry)
+
 	return cfg
 }
 
 func buildHTTPClient(opts *option.Options) Doer {
+	var d Doer = http.DefaultClient
 	if opts.HTTPClient != nil {
-		return opts.HTTPClient
+		d = opts.HTTPClient
+	}
+
+	mws := append([]option.Middleware{}, opts.Middlewares...)
+	if opts.Logger != nil {
+		secrets := append([]string{opts.APIKey, opts.APISecret}, opts.Redactions...)
+		mws = append(mws, StructuredLoggingMiddleware(opts.Logger, secrets...))
 	}
 
-	return http.DefaultClient
+	return Chain(d, mws...)
 }
@@ -1,11 +1,14 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/maxatome/go-testdeep/td"
@@ -31,6 +34,12 @@ func (u WithBaseURL) Apply(o *option.Options) {
 	o.BaseURL = string(u)
 }
 
+type WithRetry option.Retry
+
+func (r WithRetry) Apply(o *option.Options) {
+	o.Retry = option.Retry(r)
+}
+
 func TestSendRequest(t *testing.T) {
 	type Args struct {
 		path   string
@@ -115,7 +124,7 @@ func TestSendRequest(t *testing.T) {
 			},
 			want: Want{
 				body: "",
-				err:  "unknown error: status code: 500",
+				err:  "server error: status code: 500",
 			},
 		},
 		{
@@ -144,7 +153,7 @@ func TestSendRequest(t *testing.T) {
 			},
 			want: Want{
 				body: "",
-				err:  "rate limited with limit: 1, remaining: 2, reset: 3",
+				err:  "rate limited with limit: 1, remaining: 2, reset: 1970-01-01T00:00:03Z",
 			},
 		},
 		{
@@ -237,3 +246,220 @@ func TestSendRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestSendRequestWithContext(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := New(WithBaseURL(server.URL))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := cl.SendRequestWithContext(ctx, "/", http.MethodGet, nil)
+	assert.NotNil(t, err)
+	td.CmpTrue(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestSendRequestRetry(t *testing.T) {
+	type MockData struct {
+		status int
+		header map[string]string
+	}
+
+	type Args struct {
+		method string
+		retry  WithRetry
+	}
+
+	type Fields struct {
+		data []MockData
+	}
+
+	type Want struct {
+		requests int
+		err      string
+	}
+
+	tests := []struct {
+		name   string
+		args   Args
+		fields Fields
+		want   Want
+	}{
+		{
+			name: "disabled",
+			fields: Fields{
+				data: []MockData{
+					{status: http.StatusInternalServerError},
+					{status: http.StatusOK},
+				},
+			},
+			want: Want{
+				requests: 1,
+				err:      "server error: status code: 500",
+			},
+		},
+		{
+			name: "server_error",
+			args: Args{
+				retry: WithRetry{MaxAttempts: 3},
+			},
+			fields: Fields{
+				data: []MockData{
+					{status: http.StatusInternalServerError},
+					{status: http.StatusBadGateway},
+					{status: http.StatusOK},
+				},
+			},
+			want: Want{
+				requests: 3,
+			},
+		},
+		{
+			name: "rate_limited",
+			args: Args{
+				retry: WithRetry{MaxAttempts: 2, MinBackoff: time.Hour, MaxBackoff: time.Hour},
+			},
+			fields: Fields{
+				data: []MockData{
+					{
+						status: http.StatusTooManyRequests,
+						header: map[string]string{
+							"X-Ratelimit-Limit":     "10",
+							"X-Ratelimit-Remaining": "0",
+							"X-Ratelimit-Reset":     "1",
+						},
+					},
+					{status: http.StatusOK},
+				},
+			},
+			want: Want{
+				requests: 2,
+			},
+		},
+		{
+			name: "exhausted",
+			args: Args{
+				retry: WithRetry{MaxAttempts: 2},
+			},
+			fields: Fields{
+				data: []MockData{
+					{status: http.StatusServiceUnavailable},
+					{status: http.StatusServiceUnavailable},
+					{status: http.StatusOK},
+				},
+			},
+			want: Want{
+				requests: 2,
+				err:      "server error: status code: 503",
+			},
+		},
+		{
+			name: "client_error",
+			args: Args{
+				retry: WithRetry{MaxAttempts: 3},
+			},
+			fields: Fields{
+				data: []MockData{
+					{status: http.StatusNotFound},
+					{status: http.StatusOK},
+				},
+			},
+			want: Want{
+				requests: 1,
+				err:      "not found: status code: 404",
+			},
+		},
+		{
+			name: "idempotent_post",
+			args: Args{
+				method: http.MethodPost,
+				retry:  WithRetry{MaxAttempts: 3},
+			},
+			fields: Fields{
+				data: []MockData{
+					{status: http.StatusInternalServerError},
+					{status: http.StatusInternalServerError},
+					{status: http.StatusOK},
+				},
+			},
+			want: Want{
+				requests: 3,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodGet
+			if tt.args.method != "" {
+				method = tt.args.method
+			}
+
+			retry := tt.args.retry
+			if retry.MinBackoff == 0 {
+				retry.MinBackoff = time.Millisecond
+			}
+
+			var keys []string
+			var bodies []string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+				keys = append(keys, req.Header.Get("Idempotency-Key"))
+				b, _ := io.ReadAll(req.Body)
+				bodies = append(bodies, string(b))
+
+				data := tt.fields.data[len(keys)-1]
+				for k, v := range data.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(data.status)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := New(WithBaseURL(server.URL), retry)
+
+			_, err := cl.SendRequest("/", method, strings.NewReader("payload"))
+			td.Cmp(t, len(keys), tt.want.requests)
+			for i := range keys {
+				td.Cmp(t, keys[i], keys[0])
+				td.Cmp(t, bodies[i], "payload")
+			}
+			if tt.want.err != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestSendRequestRetryWithContext(t *testing.T) {
+	var requests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := New(WithBaseURL(server.URL), WithRetry{MaxAttempts: 5, MinBackoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := cl.SendRequestWithContext(ctx, "/", http.MethodGet, nil)
+	assert.NotNil(t, err)
+	td.CmpTrue(t, errors.Is(err, context.DeadlineExceeded))
+	td.Cmp(t, requests, 1)
+}
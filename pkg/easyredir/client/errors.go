@@ -0,0 +1,35 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrUnknown        = errors.New("unknown error")
+	ErrNotFound       = errors.New("not found")
+	ErrValidation     = errors.New("validation failed")
+	ErrAuthentication = errors.New("authentication failed")
+	ErrConflict       = errors.New("conflict")
+	ErrRateLimited    = errors.New("rate limited")
+	ErrServer         = errors.New("server error")
+)
+
+func statusKind(status int) error {
+	switch {
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusBadRequest, status == http.StatusUnprocessableEntity:
+		return ErrValidation
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return ErrAuthentication
+	case status == http.StatusConflict:
+		return ErrConflict
+	case status == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case status >= http.StatusInternalServerError:
+		return ErrServer
+	}
+
+	return nil
+}
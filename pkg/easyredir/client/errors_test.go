@@ -0,0 +1,300 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestAPIErrorsIs(t *testing.T) {
+	type Fields struct {
+		status int
+		body   string
+	}
+
+	type Want struct {
+		is    error
+		isNot []error
+		field *APIError
+	}
+
+	tests := []struct {
+		name   string
+		fields Fields
+		want   Want
+	}{
+		{
+			name: "not_found",
+			fields: Fields{
+				status: http.StatusNotFound,
+				body: `
+					{
+					  "type": "record_not_found_error",
+					  "message": "Record not found"
+					}
+				`,
+			},
+			want: Want{
+				is:    ErrNotFound,
+				isNot: []error{ErrValidation, ErrServer},
+			},
+		},
+		{
+			name: "validation",
+			fields: Fields{
+				status: http.StatusUnprocessableEntity,
+				body: `
+					{
+					  "type": "invalid_request_error",
+					  "message": "Invalid Request",
+					  "errors": [
+					    {
+					      "resource": "rule",
+					      "param": "forward_params",
+					      "code": "invalid_option",
+					      "message": "Must be true or false"
+					    }
+					  ]
+					}
+				`,
+			},
+			want: Want{
+				is:    ErrValidation,
+				isNot: []error{ErrNotFound},
+				field: &APIError{
+					Resource: "rule",
+					Param:    "forward_params",
+					Code:     ErrorCodeInvalidOption,
+					Message:  "Must be true or false",
+				},
+			},
+		},
+		{
+			name: "taken",
+			fields: Fields{
+				status: http.StatusUnprocessableEntity,
+				body: `
+					{
+					  "type": "invalid_request_error",
+					  "message": "Invalid Request",
+					  "errors": [
+					    {
+					      "resource": "rule",
+					      "param": "source_urls",
+					      "code": "taken",
+					      "message": "Has already been taken"
+					    }
+					  ]
+					}
+				`,
+			},
+			want: Want{
+				is:    ErrConflict,
+				isNot: []error{ErrValidation},
+				field: &APIError{
+					Resource: "rule",
+					Param:    "source_urls",
+					Code:     ErrorCodeTaken,
+					Message:  "Has already been taken",
+				},
+			},
+		},
+		{
+			name: "type_over_status",
+			fields: Fields{
+				status: http.StatusNotFound,
+				body:   `{"type": "invalid_request_error", "message": "Invalid Request"}`,
+			},
+			want: Want{
+				is:    ErrValidation,
+				isNot: []error{ErrNotFound},
+			},
+		},
+		{
+			name: "authentication",
+			fields: Fields{
+				status: http.StatusUnauthorized,
+				body:   `{"type": "unauthorized", "message": "Unauthorized"}`,
+			},
+			want: Want{
+				is: ErrAuthentication,
+			},
+		},
+		{
+			name: "conflict",
+			fields: Fields{
+				status: http.StatusConflict,
+				body:   `{"type": "conflict", "message": "Conflict"}`,
+			},
+			want: Want{
+				is: ErrConflict,
+			},
+		},
+		{
+			name: "server",
+			fields: Fields{
+				status: http.StatusInternalServerError,
+				body:   `{"type": "api_error", "message": "Internal Server Error"}`,
+			},
+			want: Want{
+				is: ErrServer,
+			},
+		},
+		{
+			name: "rate_limited",
+			fields: Fields{
+				status: http.StatusTooManyRequests,
+			},
+			want: Want{
+				is:    ErrRateLimited,
+				isNot: []error{ErrServer},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(tt.fields.status)
+				w.Write([]byte(tt.fields.body))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := New(WithBaseURL(server.URL))
+
+			_, err := cl.SendRequest("/", http.MethodGet, nil)
+			td.CmpTrue(t, errors.Is(err, tt.want.is))
+			for _, e := range tt.want.isNot {
+				td.CmpFalse(t, errors.Is(err, e))
+			}
+
+			var apiErrs APIErrors
+			if errors.As(err, &apiErrs) {
+				td.Cmp(t, apiErrs.StatusCode, tt.fields.status)
+			}
+
+			if tt.want.field != nil {
+				var apiErr APIError
+				td.CmpTrue(t, errors.As(err, &apiErr))
+				td.Cmp(t, apiErr, *tt.want.field)
+			}
+		})
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	type Fields struct {
+		status int
+		body   string
+	}
+
+	type Want struct {
+		err       string
+		body      string
+		apiErrors bool
+		is        error
+	}
+
+	tests := []struct {
+		name   string
+		fields Fields
+		want   Want
+	}{
+		{
+			name: "unknown",
+			fields: Fields{
+				status: http.StatusBadGateway,
+				body:   "<html>Bad Gateway</html>",
+			},
+			want: Want{
+				err:  "server error: status code: 502, request id: req-123",
+				body: "<html>Bad Gateway</html>",
+				is:   ErrServer,
+			},
+		},
+		{
+			name: "uncategorised",
+			fields: Fields{
+				status: http.StatusTeapot,
+				body:   "I'm a teapot",
+			},
+			want: Want{
+				err:  "unknown error: status code: 418, request id: req-123",
+				body: "I'm a teapot",
+				is:   ErrUnknown,
+			},
+		},
+		{
+			name: "api_errors",
+			fields: Fields{
+				status: http.StatusNotFound,
+				body:   `{"type":"record_not_found_error","message":"Record not found"}`,
+			},
+			want: Want{
+				err:       "record_not_found_error: Record not found",
+				body:      `{"type":"record_not_found_error","message":"Record not found"}`,
+				apiErrors: true,
+				is:        ErrNotFound,
+			},
+		},
+		{
+			name: "rate_limited",
+			fields: Fields{
+				status: http.StatusTooManyRequests,
+				body:   "slow down",
+			},
+			want: Want{
+				err:  "rate limited",
+				body: "slow down",
+				is:   ErrRateLimited,
+			},
+		},
+		{
+			name: "truncated",
+			fields: Fields{
+				status: http.StatusInternalServerError,
+				body:   strings.Repeat("x", MaxErrorBodySize+100),
+			},
+			want: Want{
+				err:  "server error: status code: 500",
+				body: strings.Repeat("x", MaxErrorBodySize),
+				is:   ErrServer,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+				w.Header().Set("X-Request-Id", "req-123")
+				w.WriteHeader(tt.fields.status)
+				w.Write([]byte(tt.fields.body))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := New(WithBaseURL(server.URL))
+
+			_, err := cl.SendRequest("/", http.MethodGet, nil)
+			td.CmpContains(t, err, tt.want.err)
+			td.CmpTrue(t, errors.Is(err, tt.want.is))
+
+			var respErr *ResponseError
+			td.CmpTrue(t, errors.As(err, &respErr))
+			td.Cmp(t, respErr.StatusCode, tt.fields.status)
+			td.Cmp(t, respErr.RequestID, "req-123")
+			td.Cmp(t, respErr.Header.Get("X-Request-Id"), "req-123")
+			td.Cmp(t, respErr.Body, tt.want.body)
+
+			var apiErrs APIErrors
+			td.Cmp(t, errors.As(err, &apiErrs), tt.want.apiErrors)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket shared by every request sent through a client.
+// The bucket is drained further, or paused until the reset time, whenever the
+// API reports that fewer requests remain than there are tokens. A rate of
+// zero disables local throttling and only follows the API headers, spending
+// the reported remaining requests until the reset time.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	until  time.Time
+	reset  time.Time
+}
+
+func NewLimiter(rate float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		d := l.reserve()
+		if d == 0 {
+			return nil
+		}
+
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// Update applies the rate limit reported by the API. A negative remaining
+// means the API did not report it and leaves the tokens untouched.
+func (l *Limiter) Update(limit, remaining int, reset time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	if limit > 0 && float64(limit) < l.burst {
+		l.burst = float64(limit)
+	}
+
+	if reset.After(now) {
+		l.reset = reset
+	}
+
+	if remaining < 0 {
+		return
+	}
+
+	if l.rate <= 0 {
+		l.tokens = math.Min(l.burst, float64(remaining))
+	} else {
+		l.tokens = math.Min(l.tokens, float64(remaining))
+	}
+
+	if remaining == 0 && reset.After(now) {
+		l.until = reset
+	}
+}
+
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.refill(now)
+
+	if now.Before(l.until) {
+		return l.until.Sub(now)
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	if l.rate <= 0 {
+		if l.reset.After(now) {
+			l.until = l.reset
+			return l.until.Sub(now)
+		}
+
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+func (l *Limiter) refill(now time.Time) {
+	if !l.until.IsZero() && !now.Before(l.until) {
+		l.tokens = l.burst
+		l.until = time.Time{}
+	}
+
+	elapsed := now.Sub(l.last).Seconds()
+	if elapsed > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+elapsed*l.rate)
+	}
+
+	l.last = now
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/stretchr/testify/assert"
+)
+
+type WithLimiter struct {
+	Limiter option.Limiter
+}
+
+func (l WithLimiter) Apply(o *option.Options) {
+	o.Limiter = l.Limiter
+}
+
+func TestLimiterWait(t *testing.T) {
+	type Args struct {
+		rate     float64
+		burst    int
+		requests int
+	}
+
+	type Want struct {
+		min time.Duration
+		max time.Duration
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "burst",
+			args: Args{
+				rate:     1,
+				burst:    5,
+				requests: 5,
+			},
+			want: Want{
+				max: 50 * time.Millisecond,
+			},
+		},
+		{
+			name: "throttled",
+			args: Args{
+				rate:     50,
+				burst:    1,
+				requests: 4,
+			},
+			want: Want{
+				min: 50 * time.Millisecond,
+				max: time.Second,
+			},
+		},
+		{
+			name: "unlimited",
+			args: Args{
+				requests: 100,
+			},
+			want: Want{
+				max: 50 * time.Millisecond,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLimiter(tt.args.rate, tt.args.burst)
+
+			start := time.Now()
+			for i := 0; i < tt.args.requests; i++ {
+				assert.Nil(t, l.Wait(context.Background()))
+			}
+			td.Cmp(t, time.Since(start), td.Between(tt.want.min, tt.want.max))
+		})
+	}
+}
+
+func TestLimiterWaitConcurrent(t *testing.T) {
+	l := NewLimiter(100, 1)
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, l.Wait(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	td.Cmp(t, time.Since(start), td.Gte(50*time.Millisecond))
+}
+
+func TestLimiterWaitWithContext(t *testing.T) {
+	l := NewLimiter(0, 1)
+	l.Update(10, 0, time.Now().Add(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := l.Wait(ctx)
+	td.CmpTrue(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestLimiterUpdate(t *testing.T) {
+	l := NewLimiter(0, 1)
+	l.Update(10, 0, time.Now().Add(50*time.Millisecond))
+
+	start := time.Now()
+	assert.Nil(t, l.Wait(context.Background()))
+	td.Cmp(t, time.Since(start), td.Gte(40*time.Millisecond))
+}
+
+func TestLimiterUpdateWithoutRemaining(t *testing.T) {
+	l := NewLimiter(1, 3)
+	l.Update(10, -1, time.Time{})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, l.Wait(context.Background()))
+	}
+	td.Cmp(t, time.Since(start), td.Lt(50*time.Millisecond))
+}
+
+func TestLimiterHeaderOnly(t *testing.T) {
+	l := NewLimiter(0, 10)
+	l.Update(10, 2, time.Now().Add(50*time.Millisecond))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, l.Wait(context.Background()))
+	}
+	td.Cmp(t, time.Since(start), td.Gte(40*time.Millisecond))
+}
+
+func TestClientLimiter(t *testing.T) {
+	reset := time.Now().Add(1100 * time.Millisecond).Unix()
+
+	var requests []time.Time
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, time.Now())
+		w.Header().Set("X-Ratelimit-Limit", "1")
+		w.Header().Set("X-Ratelimit-Remaining", "0")
+		w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := New(WithBaseURL(server.URL), WithLimiter{Limiter: NewLimiter(0, 10)})
+
+	for i := 0; i < 2; i++ {
+		_, err := cl.SendRequest("/", http.MethodGet, nil)
+		assert.Nil(t, err)
+	}
+
+	td.Cmp(t, len(requests), 2)
+	td.Cmp(t, requests[1], td.Gte(time.Unix(reset, 0)))
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+)
+
+const (
+	Redacted = "[REDACTED]"
+)
+
+// StructuredLoggingMiddleware logs every request and its outcome. Headers and
+// bodies are only logged when the logger has debug enabled. The Authorization
+// header is never logged and every secret is replaced wherever it appears.
+func StructuredLoggingMiddleware(l *slog.Logger, secrets ...string) option.Middleware {
+	r := redactor(secrets)
+
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			debug := l.Enabled(ctx, slog.LevelDebug)
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("path", r.Replace(req.URL.RequestURI())),
+			}
+
+			if key := req.Header.Get("Idempotency-Key"); key != "" {
+				attrs = append(attrs, slog.String("idempotency_key", key))
+			}
+
+			if debug {
+				attrs = append(attrs, slog.Any("request_headers", redactHeader(req.Header, r)))
+				if body := requestBody(req); body != "" {
+					attrs = append(attrs, slog.String("request_body", r.Replace(body)))
+				}
+			}
+
+			start := time.Now()
+			resp, err := next.Do(req)
+			attrs = append(attrs, slog.Duration("latency", time.Since(start)))
+
+			if err != nil {
+				attrs = append(attrs, slog.String("error", r.Replace(err.Error())))
+				l.LogAttrs(ctx, slog.LevelError, "request failed", attrs...)
+				return resp, err
+			}
+
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+
+			if debug {
+				attrs = append(attrs, slog.Any("response_headers", redactHeader(resp.Header, r)))
+				if body := responseBody(resp); body != "" {
+					attrs = append(attrs, slog.String("response_body", r.Replace(body)))
+				}
+			}
+
+			l.LogAttrs(ctx, slog.LevelInfo, "request sent", attrs...)
+
+			return resp, nil
+		})
+	}
+}
+
+func redactor(secrets []string) *strings.Replacer {
+	var pairs []string
+	for _, s := range secrets {
+		if s != "" {
+			pairs = append(pairs, s, Redacted)
+		}
+	}
+
+	return strings.NewReplacer(pairs...)
+}
+
+func redactHeader(h http.Header, r *strings.Replacer) map[string]string {
+	m := make(map[string]string, len(h))
+	for k, v := range h {
+		if k == "Authorization" {
+			m[k] = Redacted
+			continue
+		}
+		m[k] = r.Replace(strings.Join(v, ", "))
+	}
+
+	return m
+}
+
+func requestBody(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	b, _ := io.ReadAll(body)
+
+	return string(b)
+}
+
+func responseBody(resp *http.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+)
+
+type WithLogger struct {
+	Logger *slog.Logger
+}
+
+func (l WithLogger) Apply(o *option.Options) {
+	o.Logger = l.Logger
+}
+
+type WithRedaction string
+
+func (r WithRedaction) Apply(o *option.Options) {
+	o.Redactions = append(o.Redactions, string(r))
+}
+
+func TestStructuredLoggingMiddleware(t *testing.T) {
+	type Args struct {
+		method string
+		body   string
+		level  slog.Level
+	}
+
+	type Want struct {
+		contains    []string
+		notContains []string
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "info",
+			args: Args{
+				method: http.MethodGet,
+				level:  slog.LevelInfo,
+			},
+			want: Want{
+				contains: []string{
+					"method=GET",
+					"path=/rules",
+					"status=200",
+					"latency=",
+				},
+				notContains: []string{
+					"response_body",
+					"request_headers",
+				},
+			},
+		},
+		{
+			name: "debug",
+			args: Args{
+				method: http.MethodPost,
+				body:   `{"target_url":"https://example.com/?token=top-secret"}`,
+				level:  slog.LevelDebug,
+			},
+			want: Want{
+				contains: []string{
+					"method=POST",
+					"idempotency_key=",
+					"request_body=",
+					"response_body=",
+					"Authorization:[REDACTED]",
+					"[REDACTED]",
+				},
+				notContains: []string{
+					"top-secret",
+					"my-api-secret",
+					"Basic ",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"echo":"my-api-secret"}`))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			var b bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: tt.args.level}))
+
+			cl := New(
+				WithBaseURL(server.URL),
+				WithAPIKey("my-api-key"),
+				WithAPISecret("my-api-secret"),
+				WithRedaction("top-secret"),
+				WithLogger{Logger: logger},
+			)
+
+			r, err := cl.SendRequest("/rules", tt.args.method, strings.NewReader(tt.args.body))
+			td.CmpNoError(t, err)
+			r.Close()
+
+			got := b.String()
+			for _, s := range tt.want.contains {
+				td.CmpContains(t, got, s)
+			}
+			for _, s := range tt.want.notContains {
+				td.CmpNot(t, got, td.Contains(s))
+			}
+		})
+	}
+}
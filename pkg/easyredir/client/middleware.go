@@ -0,0 +1,59 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+)
+
+type DoerFunc func(*http.Request) (*http.Response, error)
+
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// Chain wraps the doer with the middlewares so that the first middleware is
+// the outermost and sees each request first.
+func Chain(d Doer, mws ...option.Middleware) Doer {
+	for i := len(mws) - 1; i >= 0; i-- {
+		d = mws[i](d)
+	}
+
+	return d
+}
+
+func HeaderMiddleware(header http.Header) option.Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			for k, v := range header {
+				req.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+			}
+
+			return next.Do(req)
+		})
+	}
+}
+
+func LoggingMiddleware(l Logger) option.Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+
+			resp, err := next.Do(req)
+			if err != nil {
+				l.Printf("%v %v: %v (%v)", req.Method, req.URL.Path, err, time.Since(start))
+				return resp, err
+			}
+
+			l.Printf("%v %v: %v (%v)", req.Method, req.URL.Path, resp.StatusCode, time.Since(start))
+
+			return resp, nil
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/stretchr/testify/assert"
+)
+
+type WithMiddleware option.Middleware
+
+func (m WithMiddleware) Apply(o *option.Options) {
+	o.Middlewares = append(o.Middlewares, option.Middleware(m))
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+
+	mw := func(name string) option.Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next.Do(req)
+			})
+		}
+	}
+
+	d := Chain(DoerFunc(func(req *http.Request) (*http.Response, error) {
+		calls = append(calls, "doer")
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}), mw("first"), mw("second"))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	_, err := d.Do(req)
+	assert.Nil(t, err)
+	td.Cmp(t, calls, []string{"first", "second", "doer"})
+}
+
+func TestHeaderMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		td.Cmp(t, req.Header.Get("X-Trace-Id"), "abc-123")
+		td.Cmp(t, req.Header.Get("Accept"), ResourceType)
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := New(
+		WithBaseURL(server.URL),
+		WithMiddleware(HeaderMiddleware(http.Header{
+			"x-trace-id": []string{"abc-123"},
+		})),
+	)
+
+	_, err := cl.SendRequest("/", http.MethodGet, nil)
+	assert.Nil(t, err)
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		want   string
+	}{
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			want:   "GET /rules: 200",
+		},
+		{
+			name:   "not_found",
+			status: http.StatusNotFound,
+			want:   "GET /rules: 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(tt.status)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			var b bytes.Buffer
+			cl := New(
+				WithBaseURL(server.URL),
+				WithMiddleware(LoggingMiddleware(log.New(&b, "", 0))),
+			)
+
+			cl.SendRequest("/rules", http.MethodGet, nil)
+			td.CmpContains(t, b.String(), tt.want)
+		})
+	}
+}
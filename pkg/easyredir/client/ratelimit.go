@@ -0,0 +1,67 @@
+package client
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+func (cl *Client) RateLimit() RateLimit {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	return cl.rateLimit
+}
+
+func (cl *Client) setRateLimit(rl RateLimit) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.rateLimit = rl
+}
+
+func (rl RateLimit) RetryAfter() time.Duration {
+	if rl.Reset.IsZero() {
+		return 0
+	}
+
+	d := time.Until(rl.Reset)
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// rateLimitFields records which of the rate limit headers were sent.
+type rateLimitFields struct {
+	limit     bool
+	remaining bool
+	reset     bool
+}
+
+func (f rateLimitFields) any() bool {
+	return f.limit || f.remaining || f.reset
+}
+
+// parseRateLimit reads the rate limit headers, where the reset is expressed
+// in Unix epoch seconds. Headers that are missing or malformed are left at
+// their zero value and reported as absent.
+func parseRateLimit(h http.Header) (rl RateLimit, present rateLimitFields) {
+	if v, err := strconv.Atoi(h.Get("X-Ratelimit-Limit")); err == nil {
+		rl.Limit = v
+		present.limit = true
+	}
+
+	if v, err := strconv.Atoi(h.Get("X-Ratelimit-Remaining")); err == nil {
+		rl.Remaining = v
+		present.remaining = true
+	}
+
+	if v, err := strconv.ParseInt(h.Get("X-Ratelimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(v, 0)
+		present.reset = true
+	}
+
+	return rl, present
+}
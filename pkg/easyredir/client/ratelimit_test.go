@@ -0,0 +1,162 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		give        map[string]string
+		want        RateLimit
+		wantPresent rateLimitFields
+	}{
+		{
+			name: "all",
+			give: map[string]string{
+				"X-Ratelimit-Limit":     "100",
+				"X-Ratelimit-Remaining": "42",
+				"X-Ratelimit-Reset":     "1655000000",
+			},
+			want: RateLimit{
+				Limit:     100,
+				Remaining: 42,
+				Reset:     time.Unix(1655000000, 0),
+			},
+			wantPresent: rateLimitFields{
+				limit:     true,
+				remaining: true,
+				reset:     true,
+			},
+		},
+		{
+			name: "partial",
+			give: map[string]string{
+				"X-Ratelimit-Remaining": "0",
+			},
+			want: RateLimit{
+				Remaining: 0,
+			},
+			wantPresent: rateLimitFields{
+				remaining: true,
+			},
+		},
+		{
+			name: "missing_remaining",
+			give: map[string]string{
+				"X-Ratelimit-Limit": "100",
+			},
+			want: RateLimit{
+				Limit: 100,
+			},
+			wantPresent: rateLimitFields{
+				limit: true,
+			},
+		},
+		{
+			name: "invalid",
+			give: map[string]string{
+				"X-Ratelimit-Limit": "many",
+			},
+		},
+		{
+			name: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.give {
+				h.Set(k, v)
+			}
+
+			got, present := parseRateLimit(h)
+			td.Cmp(t, present, tt.wantPresent)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		give RateLimit
+		want td.TestDeep
+	}{
+		{
+			name: "future",
+			give: RateLimit{Reset: time.Now().Add(time.Minute)},
+			want: td.Between(59*time.Second, time.Minute),
+		},
+		{
+			name: "past",
+			give: RateLimit{Reset: time.Now().Add(-time.Minute)},
+			want: td.Zero(),
+		},
+		{
+			name: "unset",
+			give: RateLimit{},
+			want: td.Zero(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, tt.give.RetryAfter(), tt.want)
+		})
+	}
+}
+
+func TestClientRateLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "99")
+		w.Header().Set("X-Ratelimit-Reset", "1655000000")
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := New(WithBaseURL(server.URL))
+	td.Cmp(t, cl.RateLimit(), RateLimit{})
+
+	_, err := cl.SendRequest("/", http.MethodGet, nil)
+	assert.Nil(t, err)
+	td.Cmp(t, cl.RateLimit(), RateLimit{
+		Limit:     100,
+		Remaining: 99,
+		Reset:     time.Unix(1655000000, 0),
+	})
+}
+
+func TestRateLimitError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "0")
+		w.Header().Set("X-Ratelimit-Reset", "1655000000")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := New(WithBaseURL(server.URL))
+
+	_, err := cl.SendRequest("/", http.MethodGet, nil)
+
+	var rlErr *RateLimitError
+	td.CmpTrue(t, errors.As(err, &rlErr))
+	td.Cmp(t, rlErr.Limit, 100)
+	td.Cmp(t, rlErr.Remaining, 0)
+	td.Cmp(t, rlErr.Reset, time.Unix(1655000000, 0))
+	td.Cmp(t, rlErr.RetryAfter(), time.Duration(0))
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+)
+
+type Retry struct {
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
+
+const (
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+func buildRetry(opts option.Retry) Retry {
+	r := Retry{
+		MaxAttempts: 1,
+		MinBackoff:  DefaultMinBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+	}
+
+	if opts.MaxAttempts > 0 {
+		r.MaxAttempts = opts.MaxAttempts
+	}
+
+	if opts.MinBackoff > 0 {
+		r.MinBackoff = opts.MinBackoff
+	}
+
+	if opts.MaxBackoff > 0 {
+		r.MaxBackoff = opts.MaxBackoff
+	}
+
+	if r.MaxBackoff < r.MinBackoff {
+		r.MaxBackoff = r.MinBackoff
+	}
+
+	return r
+}
+
+func (r Retry) canRetry(attempt int) bool {
+	return attempt < r.MaxAttempts
+}
+
+// backoff returns an exponential delay for the attempt with equal jitter,
+// bounded by the maximum backoff.
+func (r Retry) backoff(attempt int) time.Duration {
+	d := r.MinBackoff
+	for i := 1; i < attempt && d < r.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.MaxBackoff {
+		d = r.MaxBackoff
+	}
+
+	half := d / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+func isRetryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
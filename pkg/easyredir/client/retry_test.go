@@ -0,0 +1,67 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	type Args struct {
+		retry   Retry
+		attempt int
+	}
+
+	type Want struct {
+		min time.Duration
+		max time.Duration
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "first",
+			args: Args{
+				retry:   Retry{MinBackoff: time.Second, MaxBackoff: time.Minute},
+				attempt: 1,
+			},
+			want: Want{
+				min: 500 * time.Millisecond,
+				max: time.Second,
+			},
+		},
+		{
+			name: "exponential",
+			args: Args{
+				retry:   Retry{MinBackoff: time.Second, MaxBackoff: time.Minute},
+				attempt: 4,
+			},
+			want: Want{
+				min: 4 * time.Second,
+				max: 8 * time.Second,
+			},
+		},
+		{
+			name: "bounded",
+			args: Args{
+				retry:   Retry{MinBackoff: time.Second, MaxBackoff: 10 * time.Second},
+				attempt: 20,
+			},
+			want: Want{
+				min: 5 * time.Second,
+				max: 10 * time.Second,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.args.retry.backoff(tt.args.attempt)
+			td.Cmp(t, got, td.Between(tt.want.min, tt.want.max))
+		})
+	}
+}
@@ -4,42 +4,66 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
 	"github.com/mikelorant/easyredir/pkg/structutil"
 )
 
-type Doer interface {
-	Do(*http.Request) (*http.Response, error)
-}
+type Doer = option.Doer
 
 type Client struct {
 	HTTPClient Doer
 	Config     *Config
+	Limiter    option.Limiter
+
+	mu        sync.Mutex
+	rateLimit RateLimit
 }
 
 type Config struct {
 	BaseURL   string
 	APIKey    string
 	APISecret string
+	Retry     Retry
 }
 
 type APIErrors struct {
-	Type    string     `json:"type"`
-	Message string     `json:"message"`
-	Errors  []APIError `json:"errors"`
+	Type       ErrorType      `json:"type"`
+	Message    string         `json:"message"`
+	Errors     []APIError     `json:"errors"`
+	StatusCode int            `json:"-"`
+	Response   *ResponseError `json:"-"`
 }
 
 type APIError struct {
-	Resource string `json:"resource"`
-	Param    string `json:"param"`
-	Code     string `json:"code"`
-	Message  string `json:"message"`
+	Resource string    `json:"resource"`
+	Param    string    `json:"param"`
+	Code     ErrorCode `json:"code"`
+	Message  string    `json:"message"`
+}
+
+type ErrorType string
+
+type ErrorCode string
+
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Reset     time.Time
 }
 
 type RateLimitError struct {
-	Limit     string
-	Remaining string
-	Reset     string
+	RateLimit
+	Response *ResponseError
+}
+
+type ResponseError struct {
+	StatusCode int
+	Header     http.Header
+	Body       string
+	RequestID  string
 }
 
 const (
@@ -50,6 +74,25 @@ const (
 	ResourceType = "application/json; charset=utf-8"
 )
 
+const (
+	MaxErrorBodySize    = 4096
+	maxResponseBodySize = 1 << 20
+)
+
+const (
+	ErrorTypeInvalidRequest ErrorType = "invalid_request_error"
+	ErrorTypeRecordNotFound ErrorType = "record_not_found_error"
+	ErrorTypeAuthentication ErrorType = "authentication_error"
+	ErrorTypeAPI            ErrorType = "api_error"
+)
+
+const (
+	ErrorCodeRequired      ErrorCode = "required"
+	ErrorCodeInvalid       ErrorCode = "invalid"
+	ErrorCodeInvalidOption ErrorCode = "invalid_option"
+	ErrorCodeTaken         ErrorCode = "taken"
+)
+
 func (err APIErrors) Error() string {
 	var sb strings.Builder
 
@@ -65,6 +108,82 @@ func (err APIErrors) Error() string {
 	return sb.String()
 }
 
+func (err APIErrors) Is(target error) bool {
+	return target != nil && target == err.Kind()
+}
+
+func (err APIErrors) Unwrap() []error {
+	var errs []error
+	for _, e := range err.Errors {
+		errs = append(errs, e)
+	}
+
+	if err.Response != nil {
+		errs = append(errs, err.Response)
+	}
+
+	return errs
+}
+
+// Kind returns the sentinel error that categorises the error, preferring the
+// error type reported by the API over the status code. A field that is
+// already taken is reported as a conflict.
+func (err APIErrors) Kind() error {
+	for _, e := range err.Errors {
+		if e.Code == ErrorCodeTaken {
+			return ErrConflict
+		}
+	}
+
+	switch err.Type {
+	case ErrorTypeRecordNotFound:
+		return ErrNotFound
+	case ErrorTypeInvalidRequest:
+		return ErrValidation
+	case ErrorTypeAuthentication:
+		return ErrAuthentication
+	}
+
+	return statusKind(err.StatusCode)
+}
+
+func (err APIError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "%v: %v", err.Resource, err.Param)
+	if err.Code != "" {
+		fmt.Fprintf(&sb, ": %v", err.Code)
+	}
+	if err.Message != "" {
+		fmt.Fprintf(&sb, ": %v", err.Message)
+	}
+
+	return sb.String()
+}
+
+func (err RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+func (err RateLimitError) Unwrap() error {
+	if err.Response == nil {
+		return nil
+	}
+
+	return err.Response
+}
+
+func (err *ResponseError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "status code: %d", err.StatusCode)
+	if err.RequestID != "" {
+		fmt.Fprintf(&sb, ", request id: %v", err.RequestID)
+	}
+
+	return sb.String()
+}
+
 func (err RateLimitError) Error() string {
-	return fmt.Sprintf("rate limited with limit: %v, remaining: %v, reset: %v", err.Limit, err.Remaining, err.Reset)
+	return fmt.Sprintf("rate limited with limit: %v, remaining: %v, reset: %v", err.Limit, err.Remaining, err.Reset.UTC().Format(time.RFC3339))
 }
@@ -1,7 +1,8 @@
 package easyredir
 
 import (
-	"net/http"
+	"context"
+	"log/slog"
 
 	"github.com/mikelorant/easyredir/pkg/easyredir/client"
 	"github.com/mikelorant/easyredir/pkg/easyredir/host"
@@ -9,13 +10,20 @@ import (
 	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
 )
 
+var (
+	ErrNotFound       = client.ErrNotFound
+	ErrValidation     = client.ErrValidation
+	ErrAuthentication = client.ErrAuthentication
+	ErrConflict       = client.ErrConflict
+	ErrRateLimited    = client.ErrRateLimited
+	ErrServer         = client.ErrServer
+)
+
 type Easyredir struct {
 	Client *client.Client
 }
 
-type Doer interface {
-	Do(*http.Request) (*http.Response, error)
-}
+type Doer = option.Doer
 
 func New(opts ...option.Option) *Easyredir {
 	return &Easyredir{
@@ -24,31 +32,79 @@ func New(opts ...option.Option) *Easyredir {
 }
 
 func (c *Easyredir) CreateRule(attr rule.Attributes, opts ...option.Option) (r rule.Rule, err error) {
-	return rule.CreateRule(c.Client, attr, opts...)
+	return c.CreateRuleWithContext(context.Background(), attr, opts...)
+}
+
+func (c *Easyredir) CreateRuleWithContext(ctx context.Context, attr rule.Attributes, opts ...option.Option) (r rule.Rule, err error) {
+	return rule.CreateRuleWithContext(ctx, c.Client, attr, opts...)
+}
+
+func (c *Easyredir) GetRule(id string, opts ...option.Option) (r rule.Rule, err error) {
+	return c.GetRuleWithContext(context.Background(), id, opts...)
+}
+
+func (c *Easyredir) GetRuleWithContext(ctx context.Context, id string, opts ...option.Option) (r rule.Rule, err error) {
+	return rule.GetRuleWithContext(ctx, c.Client, id, opts...)
 }
 
 func (c *Easyredir) ListRules(opts ...option.Option) (r rule.Rules, err error) {
-	return rule.ListRulesPaginator(c.Client, opts...)
+	return c.ListRulesWithContext(context.Background(), opts...)
+}
+
+func (c *Easyredir) ListRulesWithContext(ctx context.Context, opts ...option.Option) (r rule.Rules, err error) {
+	return rule.ListRulesPaginatorWithContext(ctx, c.Client, opts...)
+}
+
+func (c *Easyredir) IterateRules(ctx context.Context, opts ...option.Option) *rule.Iterator {
+	return rule.ListRulesIterator(ctx, c.Client, opts...)
 }
 
 func (c *Easyredir) RemoveRule(id string) (res bool, err error) {
-	return rule.RemoveRule(c.Client, id)
+	return c.RemoveRuleWithContext(context.Background(), id)
+}
+
+func (c *Easyredir) RemoveRuleWithContext(ctx context.Context, id string) (res bool, err error) {
+	return rule.RemoveRuleWithContext(ctx, c.Client, id)
 }
 
 func (c *Easyredir) UpdateRule(id string, attr rule.Attributes, opts ...option.Option) (r rule.Rule, err error) {
-	return rule.UpdateRule(c.Client, id, attr, opts...)
+	return c.UpdateRuleWithContext(context.Background(), id, attr, opts...)
+}
+
+func (c *Easyredir) UpdateRuleWithContext(ctx context.Context, id string, attr rule.Attributes, opts ...option.Option) (r rule.Rule, err error) {
+	return rule.UpdateRuleWithContext(ctx, c.Client, id, attr, opts...)
 }
 
 func (c *Easyredir) GetHost(id string) (h host.Host, err error) {
-	return host.GetHost(c.Client, id)
+	return c.GetHostWithContext(context.Background(), id)
+}
+
+func (c *Easyredir) GetHostWithContext(ctx context.Context, id string) (h host.Host, err error) {
+	return host.GetHostWithContext(ctx, c.Client, id)
 }
 
 func (c *Easyredir) ListHosts(opts ...option.Option) (h host.Hosts, err error) {
-	return host.ListHostsPaginator(c.Client, opts...)
+	return c.ListHostsWithContext(context.Background(), opts...)
+}
+
+func (c *Easyredir) ListHostsWithContext(ctx context.Context, opts ...option.Option) (h host.Hosts, err error) {
+	return host.ListHostsPaginatorWithContext(ctx, c.Client, opts...)
+}
+
+func (c *Easyredir) IterateHosts(ctx context.Context, opts ...option.Option) *host.Iterator {
+	return host.ListHostsIterator(ctx, c.Client, opts...)
 }
 
 func (c *Easyredir) UpdateHost(id string, attr host.Attributes, opts ...option.Option) (h host.Host, err error) {
-	return host.UpdateHost(c.Client, id, attr, opts...)
+	return c.UpdateHostWithContext(context.Background(), id, attr, opts...)
+}
+
+func (c *Easyredir) UpdateHostWithContext(ctx context.Context, id string, attr host.Attributes, opts ...option.Option) (h host.Host, err error) {
+	return host.UpdateHostWithContext(ctx, c.Client, id, attr, opts...)
+}
+
+func (c *Easyredir) RateLimit() client.RateLimit {
+	return c.Client.RateLimit()
 }
 
 type WithLimit int
@@ -57,6 +113,22 @@ func (l WithLimit) Apply(o *option.Options) {
 	o.Limit = int(l)
 }
 
+type WithStartingAfter string
+
+func (s WithStartingAfter) Apply(o *option.Options) {
+	o.Pagination = option.Pagination{
+		StartingAfter: string(s),
+	}
+}
+
+type WithEndingBefore string
+
+func (e WithEndingBefore) Apply(o *option.Options) {
+	o.Pagination = option.Pagination{
+		EndingBefore: string(e),
+	}
+}
+
 type WithSourceFilter string
 
 func (s WithSourceFilter) Apply(o *option.Options) {
@@ -93,10 +165,46 @@ func (i WithInclude) Apply(o *option.Options) {
 	o.Include = string(i)
 }
 
+type WithRetry option.Retry
+
+func (r WithRetry) Apply(o *option.Options) {
+	o.Retry = option.Retry(r)
+}
+
+type WithLimiter struct {
+	Limiter option.Limiter
+}
+
+func (l WithLimiter) Apply(o *option.Options) {
+	o.Limiter = l.Limiter
+}
+
+type WithLogger struct {
+	Logger *slog.Logger
+}
+
+func (l WithLogger) Apply(o *option.Options) {
+	o.Logger = l.Logger
+}
+
+type WithRedaction string
+
+func (r WithRedaction) Apply(o *option.Options) {
+	o.Redactions = append(o.Redactions, string(r))
+}
+
+type WithValidation = option.Validation
+
 type WithHTTPClient struct {
-	client Doer
+	Client Doer
 }
 
 func (c WithHTTPClient) Apply(o *option.Options) {
-	o.HTTPClient = c.client
+	o.HTTPClient = c.Client
+}
+
+type WithMiddleware option.Middleware
+
+func (m WithMiddleware) Apply(o *option.Options) {
+	o.Middlewares = append(o.Middlewares, option.Middleware(m))
 }
@@ -0,0 +1,128 @@
+package easyredirtest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+)
+
+// AddHost stores a host and returns it. The API creates hosts as rules use
+// them, this allows hosts to be set up ahead of the rules.
+func (s *Server) AddHost(name string, attr host.Attributes) host.Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addHost(name, attr)
+}
+
+// Hosts returns a copy of the stored hosts.
+func (s *Server) Hosts() []host.Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]host.Data(nil), s.hosts...)
+}
+
+func (s *Server) addHost(name string, attr host.Attributes) host.Data {
+	attr.Name = strings.ToLower(name)
+	if attr.DNSStatus == "" {
+		attr.DNSStatus = host.DNSStatusActive
+	}
+	if attr.CertificateStatus == "" {
+		attr.CertificateStatus = host.CertificateStatusActive
+	}
+	setCustom404Body(&attr, attr.NotFoundAction.Custom404Body)
+
+	d := host.Data{
+		ID:         s.nextID("host"),
+		Type:       "host",
+		Attributes: attr,
+	}
+	d.Links.Self = "/v1/hosts/" + d.ID
+	s.hosts = append(s.hosts, d)
+
+	return d
+}
+
+func (s *Server) listHosts(w http.ResponseWriter, req *http.Request) {
+	resp, ok := page(w, req, "hosts", s.hosts, func(d host.Data) string { return d.ID })
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getHost(w http.ResponseWriter, id string) {
+	i := s.hostIndex(id)
+	if i == -1 {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": s.hosts[i]})
+}
+
+func (s *Server) updateHost(w http.ResponseWriter, id string, body []byte) {
+	i := s.hostIndex(id)
+	if i == -1 {
+		writeNotFound(w)
+		return
+	}
+
+	var attr host.Attributes
+	if !decode(w, body, &attr) {
+		return
+	}
+
+	a := &s.hosts[i].Attributes
+	merge(&a.MatchOptions.CaseInsensitive, attr.MatchOptions.CaseInsensitive)
+	merge(&a.MatchOptions.SlashInsensitive, attr.MatchOptions.SlashInsensitive)
+	merge(&a.NotFoundAction.ForwardParams, attr.NotFoundAction.ForwardParams)
+	merge(&a.NotFoundAction.ForwardPath, attr.NotFoundAction.ForwardPath)
+	merge(&a.NotFoundAction.ResponseCode, attr.NotFoundAction.ResponseCode)
+	merge(&a.NotFoundAction.ResponseURL, attr.NotFoundAction.ResponseURL)
+	merge(&a.Security.HTTPSUpgrade, attr.Security.HTTPSUpgrade)
+	merge(&a.Security.PreventForeignEmbedding, attr.Security.PreventForeignEmbedding)
+	merge(&a.Security.HSTSIncludeSubDomains, attr.Security.HSTSIncludeSubDomains)
+	merge(&a.Security.HSTSMaxAge, attr.Security.HSTSMaxAge)
+	merge(&a.Security.HSTSPreload, attr.Security.HSTSPreload)
+	setCustom404Body(a, attr.NotFoundAction.Custom404Body)
+
+	writeJSON(w, http.StatusOK, map[string]any{"data": s.hosts[i]})
+}
+
+func (s *Server) hostIndex(id string) int {
+	return slices.IndexFunc(s.hosts, func(d host.Data) bool { return d.ID == id })
+}
+
+func (s *Server) hostByName(name string) *host.Data {
+	for i := range s.hosts {
+		if strings.EqualFold(s.hosts[i].Attributes.Name, name) {
+			return &s.hosts[i]
+		}
+	}
+
+	return nil
+}
+
+// setCustom404Body records that a host has a custom 404 body. The API only
+// reports whether a body is set and never returns it.
+func setCustom404Body(a *host.Attributes, body *string) {
+	if body == nil {
+		return
+	}
+
+	present := true
+	a.NotFoundAction.Custom404Body = nil
+	a.NotFoundAction.Custom404BodyPresent = &present
+}
+
+// merge sets dst when the patch has a value.
+func merge[T any](dst **T, v *T) {
+	if v != nil {
+		*dst = v
+	}
+}
@@ -0,0 +1,256 @@
+package easyredirtest
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/sourceurl"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+// AddRule stores a rule as if it was created through the API and returns it.
+// Hosts for the source URLs are added when missing.
+func (s *Server) AddRule(attr rule.Attributes) rule.Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addRule(attr)
+}
+
+// Rules returns a copy of the stored rules.
+func (s *Server) Rules() []rule.Data {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]rule.Data(nil), s.rules...)
+}
+
+func (s *Server) addRule(attr rule.Attributes) rule.Data {
+	if attr.ForwardParams == nil {
+		attr.ForwardParams = new(bool)
+	}
+	if attr.ForwardPath == nil {
+		attr.ForwardPath = new(bool)
+	}
+	if attr.ResponseType == nil {
+		rt := rule.ResponseMovedPermanently
+		attr.ResponseType = &rt
+	}
+
+	d := rule.Data{
+		ID:         s.nextID("rule"),
+		Type:       "rule",
+		Attributes: attr,
+	}
+	d.Relationships = s.relationships(d.ID, attr.SourceURLs)
+	s.rules = append(s.rules, d)
+
+	return d
+}
+
+func (s *Server) listRules(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+
+	var rules []rule.Data
+	for _, d := range s.rules {
+		if sq := q.Get("sq"); sq != "" && !slices.ContainsFunc(d.Attributes.SourceURLs, contains(sq)) {
+			continue
+		}
+		if tq := q.Get("tq"); tq != "" && (d.Attributes.TargetURL == nil || !contains(tq)(*d.Attributes.TargetURL)) {
+			continue
+		}
+		rules = append(rules, d)
+	}
+
+	resp, ok := page(w, req, "rules", rules, func(d rule.Data) string { return d.ID })
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getRule(w http.ResponseWriter, req *http.Request, id string) {
+	if !validIncludes(w, req) {
+		return
+	}
+
+	i := s.ruleIndex(id)
+	if i == -1 {
+		writeNotFound(w)
+		return
+	}
+
+	s.writeRule(w, req, http.StatusOK, s.rules[i])
+}
+
+func (s *Server) createRule(w http.ResponseWriter, req *http.Request, body []byte) {
+	if !validIncludes(w, req) {
+		return
+	}
+
+	var attr rule.Attributes
+	if !decode(w, body, &attr) {
+		return
+	}
+
+	var errs []client.APIError
+	if len(attr.SourceURLs) == 0 {
+		errs = append(errs, client.APIError{Resource: "rule", Param: "source_urls", Code: client.ErrorCodeRequired, Message: "source_urls is required"})
+	}
+	if attr.TargetURL == nil || *attr.TargetURL == "" {
+		errs = append(errs, client.APIError{Resource: "rule", Param: "target_url", Code: client.ErrorCodeRequired, Message: "target_url is required"})
+	}
+	errs = append(errs, s.taken("", attr.SourceURLs)...)
+	if len(errs) > 0 {
+		writeError(w, http.StatusUnprocessableEntity, client.ErrorTypeInvalidRequest, "Invalid Request", errs...)
+		return
+	}
+
+	s.writeRule(w, req, http.StatusCreated, s.addRule(attr))
+}
+
+func (s *Server) updateRule(w http.ResponseWriter, req *http.Request, id string, body []byte) {
+	if !validIncludes(w, req) {
+		return
+	}
+
+	i := s.ruleIndex(id)
+	if i == -1 {
+		writeNotFound(w)
+		return
+	}
+
+	var attr rule.Attributes
+	if !decode(w, body, &attr) {
+		return
+	}
+
+	if errs := s.taken(id, attr.SourceURLs); len(errs) > 0 {
+		writeError(w, http.StatusUnprocessableEntity, client.ErrorTypeInvalidRequest, "Invalid Request", errs...)
+		return
+	}
+
+	d := &s.rules[i]
+	if attr.ForwardParams != nil {
+		d.Attributes.ForwardParams = attr.ForwardParams
+	}
+	if attr.ForwardPath != nil {
+		d.Attributes.ForwardPath = attr.ForwardPath
+	}
+	if attr.ResponseType != nil {
+		d.Attributes.ResponseType = attr.ResponseType
+	}
+	if attr.SourceURLs != nil {
+		d.Attributes.SourceURLs = attr.SourceURLs
+		d.Relationships = s.relationships(d.ID, attr.SourceURLs)
+	}
+	if attr.TargetURL != nil {
+		d.Attributes.TargetURL = attr.TargetURL
+	}
+
+	s.writeRule(w, req, http.StatusOK, *d)
+}
+
+func (s *Server) removeRule(w http.ResponseWriter, id string) {
+	i := s.ruleIndex(id)
+	if i == -1 {
+		writeNotFound(w)
+		return
+	}
+
+	s.rules = append(s.rules[:i], s.rules[i+1:]...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validIncludes rejects unknown include[] values before a request changes
+// any state.
+func validIncludes(w http.ResponseWriter, req *http.Request) bool {
+	for _, inc := range req.URL.Query()["include[]"] {
+		if inc != "source_hosts" {
+			writeInvalid(w, "rule", "include", client.ErrorCodeInvalidOption, fmt.Sprintf("unknown include: %v", inc))
+			return false
+		}
+	}
+
+	return true
+}
+
+// writeRule sends a single rule, with its source hosts when they were asked
+// for with include[].
+func (s *Server) writeRule(w http.ResponseWriter, req *http.Request, status int, d rule.Data) {
+	resp := map[string]any{
+		"data": d,
+	}
+
+	if slices.Contains(req.URL.Query()["include[]"], "source_hosts") {
+		included := []host.Data{}
+		for _, ref := range d.Relationships.SourceHosts.Data {
+			if i := s.hostIndex(ref.ID); i != -1 {
+				included = append(included, s.hosts[i])
+			}
+		}
+		resp["included"] = included
+	}
+
+	writeJSON(w, status, resp)
+}
+
+// taken reports source URLs already used by a rule other than id.
+func (s *Server) taken(id string, sources []string) (errs []client.APIError) {
+	for _, src := range sources {
+		for _, d := range s.rules {
+			if d.ID != id && slices.ContainsFunc(d.Attributes.SourceURLs, func(o string) bool { return strings.EqualFold(o, src) }) {
+				errs = append(errs, client.APIError{Resource: "rule", Param: "source_urls", Code: client.ErrorCodeTaken, Message: fmt.Sprintf("%v is already in use", src)})
+			}
+		}
+	}
+
+	return errs
+}
+
+// relationships links the rule to the hosts of its source URLs, adding hosts
+// that do not exist yet.
+func (s *Server) relationships(id string, sources []string) rule.Relationships {
+	r := rule.Relationships{
+		SourceHosts: rule.SourceHosts{
+			Links: rule.SourceHostsLinks{
+				Related: fmt.Sprintf("/v1/rules/%v/hosts", id),
+			},
+		},
+	}
+
+	for _, src := range sources {
+		name := sourceurl.Hostname(src)
+		if name == "" {
+			continue
+		}
+
+		h := s.hostByName(name)
+		if h == nil {
+			d := s.addHost(name, host.Attributes{})
+			h = &d
+		}
+
+		ref := rule.SourceHostData{ID: h.ID, Type: "host"}
+		if !slices.Contains(r.SourceHosts.Data, ref) {
+			r.SourceHosts.Data = append(r.SourceHosts.Data, ref)
+		}
+	}
+
+	return r
+}
+
+func (s *Server) ruleIndex(id string) int {
+	return slices.IndexFunc(s.rules, func(d rule.Data) bool { return d.ID == id })
+}
+
+func contains(sub string) func(string) bool {
+	return func(s string) bool {
+		return strings.Contains(strings.ToLower(s), strings.ToLower(sub))
+	}
+}
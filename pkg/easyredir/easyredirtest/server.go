@@ -0,0 +1,360 @@
+package easyredirtest
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir"
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+const (
+	APIKey    = "test-key"
+	APISecret = "test-secret"
+)
+
+const (
+	DefaultLimit = 25
+	MaxLimit     = 100
+)
+
+// Server is a stateful fake of the EasyRedir API. Rules and hosts are kept in
+// creation order, which is also the pagination order.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	seq         int
+	rules       []rule.Data
+	hosts       []host.Data
+	idempotency map[string]response
+	faults      []Fault
+	requests    []string
+}
+
+// Fault makes matching requests fail with the status. An empty method or path
+// matches every request, the path matches by prefix. A fault is used Times
+// times, or once when Times is zero.
+type Fault struct {
+	Method string
+	Path   string
+	Status int
+	Times  int
+}
+
+type response struct {
+	request string
+	status  int
+	body    []byte
+}
+
+// NewServer starts a server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		idempotency: make(map[string]response),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+// Options returns the client options to talk to the server.
+func (s *Server) Options() []option.Option {
+	return []option.Option{
+		easyredir.WithBaseURL(s.URL),
+		easyredir.WithAPIKey(APIKey),
+		easyredir.WithAPISecret(APISecret),
+	}
+}
+
+// Client returns a client for the server. Extra options are applied after the
+// server options.
+func (s *Server) Client(opts ...option.Option) *client.Client {
+	return client.New(append(s.Options(), opts...)...)
+}
+
+// InjectFault queues a fault. Faults are checked in the order they were
+// added.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f.Times <= 0 {
+		f.Times = 1
+	}
+	s.faults = append(s.faults, f)
+}
+
+// Requests returns each request received as the method and the request URI.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req.Method+" "+req.URL.RequestURI())
+
+	if f, ok := s.fault(req); ok {
+		writeFault(w, f)
+		return
+	}
+
+	if !authorized(req) {
+		writeError(w, http.StatusUnauthorized, client.ErrorTypeAuthentication, "Invalid API key or secret")
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, client.ErrorTypeInvalidRequest, "Unable to read body")
+		return
+	}
+
+	key := req.Header.Get("Idempotency-Key")
+	if key == "" {
+		s.route(w, req, body)
+		return
+	}
+
+	fingerprint := fmt.Sprintf("%v %v %s", req.Method, req.URL.RequestURI(), body)
+	if prev, ok := s.idempotency[key]; ok {
+		if prev.request != fingerprint {
+			writeError(w, http.StatusBadRequest, client.ErrorTypeInvalidRequest, "Idempotency key reused with a different request")
+			return
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(prev.status)
+		w.Write(prev.body)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	s.route(rec, req, body)
+
+	// Only completed requests are replayed, a failure can be retried.
+	if rec.Code < http.StatusInternalServerError {
+		s.idempotency[key] = response{request: fingerprint, status: rec.Code, body: rec.Body.Bytes()}
+	}
+
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+func (s *Server) route(w http.ResponseWriter, req *http.Request, body []byte) {
+	path := strings.TrimPrefix(req.URL.Path, "/v1")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case parts[0] == "rules" && len(parts) == 1:
+		switch req.Method {
+		case http.MethodGet:
+			s.listRules(w, req)
+		case http.MethodPost:
+			s.createRule(w, req, body)
+		default:
+			writeMethodNotAllowed(w)
+		}
+
+	case parts[0] == "rules" && len(parts) == 2:
+		switch req.Method {
+		case http.MethodGet:
+			s.getRule(w, req, parts[1])
+		case http.MethodPatch:
+			s.updateRule(w, req, parts[1], body)
+		case http.MethodDelete:
+			s.removeRule(w, parts[1])
+		default:
+			writeMethodNotAllowed(w)
+		}
+
+	case parts[0] == "hosts" && len(parts) == 1:
+		switch req.Method {
+		case http.MethodGet:
+			s.listHosts(w, req)
+		default:
+			writeMethodNotAllowed(w)
+		}
+
+	case parts[0] == "hosts" && len(parts) == 2:
+		switch req.Method {
+		case http.MethodGet:
+			s.getHost(w, parts[1])
+		case http.MethodPatch:
+			s.updateHost(w, parts[1], body)
+		default:
+			writeMethodNotAllowed(w)
+		}
+
+	default:
+		writeNotFound(w)
+	}
+}
+
+func (s *Server) fault(req *http.Request) (Fault, bool) {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != req.Method {
+			continue
+		}
+		if !strings.HasPrefix(req.URL.Path, f.Path) {
+			continue
+		}
+
+		s.faults[i].Times--
+		if s.faults[i].Times == 0 {
+			s.faults = append(s.faults[:i], s.faults[i+1:]...)
+		}
+
+		return f, true
+	}
+
+	return Fault{}, false
+}
+
+func (s *Server) nextID(kind string) string {
+	s.seq++
+
+	return fmt.Sprintf("%v-%d", kind, s.seq)
+}
+
+func authorized(req *http.Request) bool {
+	key, secret, ok := req.BasicAuth()
+
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(key), []byte(APIKey)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(secret), []byte(APISecret)) == 1
+}
+
+// page returns the window of items selected by the list parameters along with
+// the metadata and links the API sends.
+func page[T any](w http.ResponseWriter, req *http.Request, name string, items []T, id func(T) string) (resp map[string]any, ok bool) {
+	q := req.URL.Query()
+
+	limit := DefaultLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeInvalid(w, name, "limit", client.ErrorCodeInvalid, "limit must be a positive number")
+			return nil, false
+		}
+		limit = min(n, MaxLimit)
+	}
+
+	index := func(param string) (int, bool) {
+		for i, item := range items {
+			if id(item) == q.Get(param) {
+				return i, true
+			}
+		}
+		writeInvalid(w, name, param, client.ErrorCodeInvalid, "cursor not found")
+		return 0, false
+	}
+
+	start, end := 0, min(limit, len(items))
+	hasMore := end < len(items)
+
+	switch {
+	case q.Get("starting_after") != "":
+		i, ok := index("starting_after")
+		if !ok {
+			return nil, false
+		}
+		start, end = i+1, min(i+1+limit, len(items))
+		hasMore = end < len(items)
+	case q.Get("ending_before") != "":
+		i, ok := index("ending_before")
+		if !ok {
+			return nil, false
+		}
+		start, end = max(i-limit, 0), i
+		hasMore = start > 0
+	}
+
+	data := append([]T{}, items[start:end]...)
+	links := option.Links{}
+	if len(data) > 0 {
+		links.Next = fmt.Sprintf("/v1/%v?starting_after=%v", name, id(data[len(data)-1]))
+		links.Prev = fmt.Sprintf("/v1/%v?ending_before=%v", name, id(data[0]))
+	}
+
+	return map[string]any{
+		"data":  data,
+		"meta":  option.Metadata{HasMore: hasMore},
+		"links": links,
+	}, true
+}
+
+// decode reads a JSON body strictly so that tests catch fields the API would
+// not know about.
+func decode(w http.ResponseWriter, body []byte, v any) bool {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, client.ErrorTypeInvalidRequest, "Invalid JSON: "+err.Error())
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", client.ResourceType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, typ client.ErrorType, msg string, errs ...client.APIError) {
+	writeJSON(w, status, client.APIErrors{
+		Type:    typ,
+		Message: msg,
+		Errors:  errs,
+	})
+}
+
+func writeInvalid(w http.ResponseWriter, resource, param string, code client.ErrorCode, msg string) {
+	writeError(w, http.StatusUnprocessableEntity, client.ErrorTypeInvalidRequest, "Invalid Request", client.APIError{
+		Resource: resource,
+		Param:    param,
+		Code:     code,
+		Message:  msg,
+	})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, client.ErrorTypeRecordNotFound, "Record not found")
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, client.ErrorTypeInvalidRequest, "Method not allowed")
+}
+
+func writeFault(w http.ResponseWriter, f Fault) {
+	if f.Status == http.StatusTooManyRequests {
+		w.Header().Set("X-Ratelimit-Limit", "100")
+		w.Header().Set("X-Ratelimit-Remaining", "0")
+		w.Header().Set("X-Ratelimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+		writeError(w, f.Status, client.ErrorTypeAPI, "Too many requests")
+		return
+	}
+
+	writeError(w, f.Status, client.ErrorTypeAPI, http.StatusText(f.Status))
+}
@@ -0,0 +1,283 @@
+package easyredirtest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleWorkflow(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	e := easyredir.New(s.Options()...)
+
+	r, err := e.CreateRule(rule.Attributes{
+		SourceURLs: []string{"abc.com", "abc.com/123"},
+		TargetURL:  ptr.String("https://otherdomain.com"),
+	}, easyredir.WithInclude("source_hosts"))
+	assert.Nil(t, err)
+	td.Cmp(t, r.Data.ID, "rule-1")
+	td.Cmp(t, r.Data.Attributes.ResponseType, td.Ptr(rule.ResponseMovedPermanently))
+	td.Cmp(t, r.Included, td.Smuggle("[0].Attributes.Name", "abc.com"))
+
+	_, err = e.CreateRule(rule.Attributes{
+		SourceURLs: []string{"xyz.com"},
+		TargetURL:  ptr.String("https://thirddomain.com"),
+	})
+	assert.Nil(t, err)
+
+	_, err = e.CreateRule(rule.Attributes{
+		SourceURLs: []string{"ABC.com"},
+		TargetURL:  ptr.String("https://otherdomain.com"),
+	})
+	td.CmpTrue(t, errors.Is(err, easyredir.ErrConflict))
+	td.CmpContains(t, err, "taken")
+
+	r, err = e.UpdateRule(r.Data.ID, rule.Attributes{
+		ResponseType: ref(rule.ResponseFound),
+	})
+	assert.Nil(t, err)
+	td.Cmp(t, r.Data.Attributes.ResponseType, td.Ptr(rule.ResponseFound))
+	td.Cmp(t, r.Data.Attributes.SourceURLs, []string{"abc.com", "abc.com/123"})
+
+	rs, err := e.ListRules(easyredir.WithSourceFilter("xyz"))
+	assert.Nil(t, err)
+	td.Cmp(t, rs.Data, td.Smuggle("[0].ID", "rule-3"))
+	td.Cmp(t, len(rs.Data), 1)
+
+	rs, err = e.ListRules(easyredir.WithTargetFilter("otherdomain"))
+	assert.Nil(t, err)
+	td.Cmp(t, len(rs.Data), 1)
+
+	ok, err := e.RemoveRule(r.Data.ID)
+	assert.Nil(t, err)
+	td.CmpTrue(t, ok)
+
+	_, err = e.GetRule(r.Data.ID)
+	td.CmpTrue(t, errors.Is(err, easyredir.ErrNotFound))
+
+	_, err = e.CreateRule(rule.Attributes{
+		SourceURLs: []string{"def.com"},
+		TargetURL:  ptr.String("https://otherdomain.com"),
+	}, easyredir.WithInclude("targets"))
+	td.CmpTrue(t, errors.Is(err, easyredir.ErrValidation))
+
+	td.Cmp(t, len(s.Rules()), 1)
+	td.Cmp(t, len(s.Hosts()), 2)
+}
+
+func TestPagination(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	var ids []string
+	for _, src := range []string{"a.com", "b.com", "c.com", "d.com", "e.com"} {
+		d := s.AddRule(rule.Attributes{SourceURLs: []string{src}, TargetURL: ptr.String("https://x.com")})
+		ids = append(ids, d.ID)
+	}
+
+	cl := s.Client()
+
+	page, err := rule.ListRules(cl, easyredir.WithLimit(2))
+	assert.Nil(t, err)
+	td.Cmp(t, len(page.Data), 2)
+	td.CmpTrue(t, page.HasMore())
+	td.Cmp(t, page.Links.Next, "/v1/rules?starting_after="+ids[1])
+
+	all, err := rule.ListRulesPaginator(cl, easyredir.WithLimit(2))
+	assert.Nil(t, err)
+	td.Cmp(t, all.Data, td.Smuggle(func(ds []rule.Data) []string {
+		var got []string
+		for _, d := range ds {
+			got = append(got, d.ID)
+		}
+		return got
+	}, ids))
+
+	var back []string
+	it := rule.ListRulesIterator(context.Background(), cl, easyredir.WithLimit(2), easyredir.WithEndingBefore(ids[4]))
+	for it.Next() {
+		back = append(back, it.Item().ID)
+	}
+	assert.Nil(t, it.Err())
+	td.Cmp(t, back, []string{ids[3], ids[2], ids[1], ids[0]})
+
+	_, err = rule.ListRules(cl, easyredir.WithStartingAfter("rule-99"))
+	td.CmpTrue(t, errors.Is(err, easyredir.ErrValidation))
+}
+
+func TestHosts(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	d := s.AddHost("ABC.com", host.Attributes{})
+
+	cl := s.Client()
+
+	hs, err := host.ListHostsPaginator(cl)
+	assert.Nil(t, err)
+	td.Cmp(t, hs.Data, td.Smuggle("[0].Attributes.Name", "abc.com"))
+
+	td.Cmp(t, d.Attributes.NotFoundAction.Custom404BodyPresent, td.Nil())
+
+	u, err := host.UpdateHost(cl, d.ID, host.Attributes{
+		NotFoundAction: host.NotFoundAction{Custom404Body: ptr.String("Not here")},
+		Security:       host.Security{HTTPSUpgrade: ptr.Bool(true)},
+	})
+	assert.Nil(t, err)
+	td.Cmp(t, u.Data.Attributes.NotFoundAction.Custom404Body, td.Nil())
+	td.Cmp(t, u.Data.Attributes.NotFoundAction.Custom404BodyPresent, td.Ptr(true))
+
+	h, err := host.GetHost(cl, d.ID)
+	assert.Nil(t, err)
+	td.Cmp(t, h.Data.Attributes.Security.HTTPSUpgrade, td.Ptr(true))
+	td.Cmp(t, h.Data.Attributes.MatchOptions.CaseInsensitive, td.Nil())
+	td.Cmp(t, h.Data.Attributes.NotFoundAction.Custom404Body, td.Nil())
+	td.Cmp(t, h.Data.Attributes.NotFoundAction.Custom404BodyPresent, td.Ptr(true))
+
+	b := s.AddHost("xyz.com", host.Attributes{
+		NotFoundAction: host.NotFoundAction{Custom404Body: ptr.String("Not here")},
+	})
+	td.Cmp(t, b.Attributes.NotFoundAction.Custom404Body, td.Nil())
+	td.Cmp(t, b.Attributes.NotFoundAction.Custom404BodyPresent, td.Ptr(true))
+
+	_, err = host.GetHost(cl, "host-99")
+	td.CmpTrue(t, errors.Is(err, easyredir.ErrNotFound))
+}
+
+func TestAuthentication(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	cl := s.Client(easyredir.WithAPISecret("wrong"))
+
+	_, err := rule.ListRules(cl)
+	td.CmpTrue(t, errors.Is(err, easyredir.ErrAuthentication))
+}
+
+func TestIdempotency(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	send := func(key, body string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, s.URL+"/rules", strings.NewReader(body))
+		req.SetBasicAuth(APIKey, APISecret)
+		req.Header.Set("Idempotency-Key", key)
+		resp, err := http.DefaultClient.Do(req)
+		assert.Nil(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	body := `{"source_urls": ["abc.com"], "target_url": "https://x.com"}`
+
+	td.Cmp(t, send("key-1", body).StatusCode, http.StatusCreated)
+
+	resp := send("key-1", body)
+	td.Cmp(t, resp.StatusCode, http.StatusCreated)
+	td.Cmp(t, resp.Header.Get("Idempotent-Replayed"), "true")
+
+	td.Cmp(t, send("key-1", `{"source_urls": ["xyz.com"], "target_url": "https://x.com"}`).StatusCode, http.StatusBadRequest)
+
+	td.Cmp(t, len(s.Rules()), 1)
+}
+
+func TestFaults(t *testing.T) {
+	type Args struct {
+		fault Fault
+		opts  []option.Option
+	}
+
+	type Want struct {
+		requests int
+		kind     error
+	}
+
+	retry := easyredir.WithRetry{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "rate_limited_retried",
+			args: Args{
+				fault: Fault{Status: http.StatusTooManyRequests, Times: 2},
+				opts:  []option.Option{retry},
+			},
+			want: Want{
+				requests: 3,
+			},
+		},
+		{
+			name: "rate_limited",
+			args: Args{
+				fault: Fault{Status: http.StatusTooManyRequests},
+			},
+			want: Want{
+				requests: 1,
+				kind:     easyredir.ErrRateLimited,
+			},
+		},
+		{
+			name: "server_error",
+			args: Args{
+				fault: Fault{Method: http.MethodPost, Path: "/rules", Status: http.StatusInternalServerError, Times: 3},
+				opts:  []option.Option{retry},
+			},
+			want: Want{
+				requests: 3,
+				kind:     easyredir.ErrServer,
+			},
+		},
+		{
+			name: "server_error_retried",
+			args: Args{
+				fault: Fault{Status: http.StatusServiceUnavailable},
+				opts:  []option.Option{retry},
+			},
+			want: Want{
+				requests: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewServer()
+			defer s.Close()
+
+			s.InjectFault(tt.args.fault)
+
+			_, err := rule.CreateRule(s.Client(tt.args.opts...), rule.Attributes{
+				SourceURLs: []string{"abc.com"},
+				TargetURL:  ptr.String("https://x.com"),
+			})
+			td.Cmp(t, len(s.Requests()), tt.want.requests)
+			if tt.want.kind != nil {
+				td.CmpTrue(t, errors.Is(err, tt.want.kind))
+				td.Cmp(t, len(s.Rules()), 0)
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, len(s.Rules()), 1)
+		})
+	}
+}
+
+func ref[T any](x T) *T {
+	return &x
+}
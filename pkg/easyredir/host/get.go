@@ -1,26 +1,19 @@
 package host
 
 import (
-	"fmt"
-	"net/http"
+	"context"
 
-	"github.com/mikelorant/easyredir/pkg/jsonutil"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/resource"
 )
 
 func GetHost(cl ClientAPI, id string) (h Host, err error) {
-	pathQuery := buildGetHost(id)
-	reader, err := cl.SendRequest(pathQuery, http.MethodGet, nil)
-	if err != nil {
-		return h, fmt.Errorf("unable to send request: %w", err)
-	}
-
-	if err := jsonutil.DecodeJSON(reader, &h); err != nil {
-		return h, fmt.Errorf("unable to get json: %w", err)
-	}
+	return GetHostWithContext(context.Background(), cl, id)
+}
 
-	return h, nil
+func GetHostWithContext(ctx context.Context, cl ClientAPI, id string) (h Host, err error) {
+	return resource.Get[Host](ctx, cl, buildGetHost(id))
 }
 
 func buildGetHost(id string) string {
-	return fmt.Sprintf("/hosts/%v", id)
+	return resource.NewQuery("hosts", id).String()
 }
@@ -1,6 +1,7 @@
 package host
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -24,6 +25,7 @@ func TestGetHosts(t *testing.T) {
 	type Want struct {
 		host Host
 		err  string
+		kind error
 	}
 
 	tests := []struct {
@@ -72,7 +74,8 @@ func TestGetHosts(t *testing.T) {
 				`,
 			},
 			want: Want{
-				err: "record_not_found_error: Record not found",
+				err:  "record_not_found_error: Record not found",
+				kind: client.ErrNotFound,
 			},
 		},
 	}
@@ -93,6 +96,7 @@ func TestGetHosts(t *testing.T) {
 			if tt.want.err != "" {
 				assert.NotNil(t, err)
 				td.CmpContains(t, err, tt.want.err)
+				td.CmpTrue(t, errors.Is(err, tt.want.kind))
 				return
 			}
 			assert.Nil(t, err)
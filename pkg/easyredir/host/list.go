@@ -1,93 +1,95 @@
 package host
 
 import (
+	"context"
 	"fmt"
-	"net/http"
-	"strings"
 
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/resource"
 	"github.com/mikelorant/easyredir/pkg/easyredir/option"
-	"github.com/mikelorant/easyredir/pkg/jsonutil"
 )
 
+type Iterator = resource.Iterator[Data]
+
 func ListHostsPaginator(cl ClientAPI, opts ...option.Option) (h Hosts, err error) {
-	h = Hosts{
-		Data: []Data{},
-	}
+	return ListHostsPaginatorWithContext(context.Background(), cl, opts...)
+}
+
+func ListHostsPaginatorWithContext(ctx context.Context, cl ClientAPI, opts ...option.Option) (h Hosts, err error) {
+	h.Data, err = resource.Paginate(ListHostsIterator(ctx, cl, opts...))
 
-	hosts := Hosts{}
-	for {
-		optsWithPage := opts
-		if hosts.HasMore() {
-			optsWithPage = append(optsWithPage, hosts.NextPage())
-		}
-
-		hosts, err = ListHosts(cl, optsWithPage...)
-		if err != nil {
-			return h, fmt.Errorf("unable to get a hosts page: %w", err)
-		}
-		h.Data = append(h.Data, hosts.Data...)
-		if !hosts.HasMore() {
-			break
-		}
+	return h, err
+}
+
+func ListHostsIterator(ctx context.Context, cl ClientAPI, opts ...option.Option) *Iterator {
+	list := func(ctx context.Context, opts ...option.Option) (resource.Page[Data], error) {
+		h, err := ListHostsWithContext(ctx, cl, opts...)
+		return resource.Page[Data](h), err
 	}
 
-	return h, nil
+	return resource.NewIterator(ctx, "hosts", list, Data.id, opts...)
 }
 
 func ListHosts(cl ClientAPI, opts ...option.Option) (h Hosts, err error) {
-	o := &option.Options{}
-	for _, opt := range opts {
-		opt.Apply(o)
-	}
+	return ListHostsWithContext(context.Background(), cl, opts...)
+}
 
-	pathQuery := buildListHosts(o)
-	reader, err := cl.SendRequest(pathQuery, http.MethodGet, nil)
+func ListHostsWithContext(ctx context.Context, cl ClientAPI, opts ...option.Option) (h Hosts, err error) {
+	o, err := option.New(option.FieldLimit|option.FieldPagination, opts...)
 	if err != nil {
-		return h, fmt.Errorf("unable to send request: %w", err)
+		return h, fmt.Errorf("unable to apply options: %w", err)
 	}
 
-	if err := jsonutil.DecodeJSON(reader, &h); err != nil {
-		return h, fmt.Errorf("unable to get json: %w", err)
+	p, err := resource.List[Data](ctx, cl, buildListHosts(o))
+
+	return Hosts(p), err
+}
+
+func (h *Hosts) NextPage() (NextPage, error) {
+	c, err := h.Links.NextCursor()
+	if err != nil {
+		return "", fmt.Errorf("unable to get next page: %w", err)
 	}
 
-	return h, nil
+	return NextPage(c), nil
 }
 
-func (h *Hosts) NextPage() NextPage {
-	return NextPage(strings.Split((h.Links.Next), "=")[1])
+func (h *Hosts) PrevPage() (PrevPage, error) {
+	c, err := h.Links.PrevCursor()
+	if err != nil {
+		return "", fmt.Errorf("unable to get previous page: %w", err)
+	}
+
+	return PrevPage(c), nil
 }
 
 type NextPage string
 
 func (np NextPage) Apply(o *option.Options) {
-	o.Pagination.StartingAfter = string(np)
-}
-
-func (h *Hosts) HasMore() bool {
-	return h.Metadata.HasMore
+	o.Pagination = option.Pagination{
+		StartingAfter: string(np),
+	}
 }
 
-func buildListHosts(opts *option.Options) string {
-	var sb strings.Builder
-	var params []string
-
-	fmt.Fprint(&sb, "/hosts")
-
-	if opts.Pagination.StartingAfter != "" {
-		params = append(params, fmt.Sprintf("starting_after=%v", opts.Pagination.StartingAfter))
-	}
+type PrevPage string
 
-	if opts.Pagination.EndingBefore != "" {
-		params = append(params, fmt.Sprintf("ending_before=%v", opts.Pagination.EndingBefore))
+func (pp PrevPage) Apply(o *option.Options) {
+	o.Pagination = option.Pagination{
+		EndingBefore: string(pp),
 	}
+}
 
-	if opts.Limit != 0 {
-		params = append(params, fmt.Sprintf("limit=%v", opts.Limit))
-	}
+func (h *Hosts) HasMore() bool {
+	return h.Metadata.HasMore
+}
 
-	if len(params) != 0 {
-		fmt.Fprintf(&sb, "?%v", strings.Join(params, "&"))
-	}
+func (d Data) id() string {
+	return d.ID
+}
 
-	return sb.String()
+func buildListHosts(opts *option.Options) string {
+	return resource.NewQuery("hosts").
+		Add("starting_after", opts.Pagination.StartingAfter).
+		Add("ending_before", opts.Pagination.EndingBefore).
+		AddInt("limit", opts.Limit).
+		String()
 }
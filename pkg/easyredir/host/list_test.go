@@ -1,6 +1,8 @@
 package host
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -228,6 +230,40 @@ func TestListHostsPaginator(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "missing_next_link",
+			fields: Fields{
+				data: []MockData{
+					{
+						status: http.StatusOK,
+						body: `
+							{
+							  "data": [
+							    {
+							      "id": "abc-def",
+							      "type": "host"
+							    }
+							  ],
+							  "meta": {
+								  "has_more": true
+							  }
+							}
+						`,
+					},
+				},
+			},
+			want: Want{
+				hosts: Hosts{
+					Data: []Data{
+						{
+							ID:   "abc-def",
+							Type: "host",
+						},
+					},
+				},
+				err: "unable to get next page: no cursor",
+			},
+		},
 		{
 			name: "invalid_page",
 			fields: Fields{
@@ -377,7 +413,7 @@ func TestBuildListHosts(t *testing.T) {
 				},
 			},
 			want: Want{
-				pathQuery: "/hosts?starting_after=96b30ce8-6331-4c18-ae49-4155c3a2136c&limit=100",
+				pathQuery: "/hosts?limit=100&starting_after=96b30ce8-6331-4c18-ae49-4155c3a2136c",
 			},
 		},
 	}
@@ -389,3 +425,305 @@ func TestBuildListHosts(t *testing.T) {
 		})
 	}
 }
+
+func TestListHostsPaginatorWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hosts/", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		cancel()
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`
+			{
+			  "data": [
+			    {
+			      "id": "abc-def",
+			      "type": "host"
+			    }
+			  ],
+			  "meta": {
+			    "has_more": true
+			  },
+			  "links": {
+			    "next": "/v1/hosts?starting_after=abc-def"
+			  }
+			}
+		`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	_, err := ListHostsPaginatorWithContext(ctx, cl)
+	assert.NotNil(t, err)
+	td.CmpTrue(t, errors.Is(err, context.Canceled))
+	td.Cmp(t, requests, 1)
+}
+
+func TestListHostsIterator(t *testing.T) {
+	pages := []string{
+		`
+			{
+			  "data": [
+			    { "id": "abc-def", "type": "host" },
+			    { "id": "bcd-efg", "type": "host" }
+			  ],
+			  "meta": { "has_more": true },
+			  "links": { "next": "/v1/hosts?starting_after=bcd-efg" }
+			}
+		`,
+		`
+			{
+			  "data": [
+			    { "id": "cde-fgh", "type": "host" }
+			  ]
+			}
+		`,
+	}
+
+	type Args struct {
+		limit int
+	}
+
+	type Want struct {
+		ids      []string
+		requests int
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "all",
+			want: Want{
+				ids:      []string{"abc-def", "bcd-efg", "cde-fgh"},
+				requests: 2,
+			},
+		},
+		{
+			name: "break_first_page",
+			args: Args{
+				limit: 1,
+			},
+			want: Want{
+				ids:      []string{"abc-def"},
+				requests: 1,
+			},
+		},
+		{
+			name: "break_second_page",
+			args: Args{
+				limit: 3,
+			},
+			want: Want{
+				ids:      []string{"abc-def", "bcd-efg", "cde-fgh"},
+				requests: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/hosts/", func(w http.ResponseWriter, req *http.Request) {
+				queries = append(queries, req.URL.RawQuery)
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(pages[len(queries)-1]))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			var ids []string
+			it := ListHostsIterator(context.Background(), cl)
+			for it.Next() {
+				ids = append(ids, it.Item().ID)
+				if len(ids) == tt.args.limit {
+					break
+				}
+			}
+			assert.Nil(t, it.Err())
+			td.Cmp(t, ids, tt.want.ids)
+			td.Cmp(t, len(queries), tt.want.requests)
+			if len(queries) > 1 {
+				td.Cmp(t, queries[1], "starting_after=bcd-efg")
+			}
+		})
+	}
+}
+
+func TestListHostsIteratorCursor(t *testing.T) {
+	pages := map[string]string{
+		"ending_before=zzz-zzz": `
+			{
+			  "data": [
+			    { "id": "ccc-ccc", "type": "host" },
+			    { "id": "ddd-ddd", "type": "host" }
+			  ],
+			  "meta": { "has_more": true },
+			  "links": { "prev": "/v1/hosts?ending_before=ccc-ccc" }
+			}
+		`,
+		"ending_before=ccc-ccc": `
+			{
+			  "data": [
+			    { "id": "aaa-aaa", "type": "host" },
+			    { "id": "bbb-bbb", "type": "host" }
+			  ]
+			}
+		`,
+		"starting_after=bbb-bbb": `
+			{
+			  "data": [
+			    { "id": "ccc-ccc", "type": "host" },
+			    { "id": "ddd-ddd", "type": "host" }
+			  ]
+			}
+		`,
+	}
+
+	type Args struct {
+		cursor option.Pagination
+		limit  int
+	}
+
+	type Want struct {
+		ids    []string
+		cursor option.Pagination
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "backward",
+			args: Args{
+				cursor: option.Pagination{EndingBefore: "zzz-zzz"},
+			},
+			want: Want{
+				ids:    []string{"ddd-ddd", "ccc-ccc", "bbb-bbb", "aaa-aaa"},
+				cursor: option.Pagination{EndingBefore: "aaa-aaa"},
+			},
+		},
+		{
+			name: "backward_partial",
+			args: Args{
+				cursor: option.Pagination{EndingBefore: "zzz-zzz"},
+				limit:  2,
+			},
+			want: Want{
+				ids:    []string{"ddd-ddd", "ccc-ccc"},
+				cursor: option.Pagination{EndingBefore: "ccc-ccc"},
+			},
+		},
+		{
+			name: "resume_backward",
+			args: Args{
+				cursor: option.Pagination{EndingBefore: "ccc-ccc"},
+			},
+			want: Want{
+				ids:    []string{"bbb-bbb", "aaa-aaa"},
+				cursor: option.Pagination{EndingBefore: "aaa-aaa"},
+			},
+		},
+		{
+			name: "resume_forward",
+			args: Args{
+				cursor: option.Pagination{StartingAfter: "bbb-bbb"},
+			},
+			want: Want{
+				ids:    []string{"ccc-ccc", "ddd-ddd"},
+				cursor: option.Pagination{StartingAfter: "ddd-ddd"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/hosts/", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(pages[req.URL.RawQuery]))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			var ids []string
+			it := ListHostsIterator(context.Background(), cl, tt.args.cursor)
+			for it.Next() {
+				ids = append(ids, it.Item().ID)
+				if len(ids) == tt.args.limit {
+					break
+				}
+			}
+			assert.Nil(t, it.Err())
+			td.Cmp(t, ids, tt.want.ids)
+			td.Cmp(t, it.Cursor(), tt.want.cursor)
+		})
+	}
+}
+
+func TestBuildHostPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{
+			name: "get",
+			give: buildGetHost("abc/def?x#y"),
+			want: "/hosts/abc%2Fdef%3Fx%23y",
+		},
+		{
+			name: "update",
+			give: buildUpdateHost("../rules"),
+			want: "/hosts/..%2Frules",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, tt.give, tt.want)
+		})
+	}
+}
+
+func TestHostsUnsupportedOptions(t *testing.T) {
+	var requests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	_, err := ListHosts(cl, WithSourceFilter("source.example.com"))
+	td.CmpTrue(t, errors.Is(err, option.ErrUnsupportedOption))
+	td.CmpContains(t, err, "source filter")
+
+	_, err = UpdateHost(cl, "abc-def", Attributes{}, WithInclude("source_hosts"))
+	td.CmpTrue(t, errors.Is(err, option.ErrUnsupportedOption))
+	td.CmpContains(t, err, "include")
+
+	td.Cmp(t, requests, 0)
+}
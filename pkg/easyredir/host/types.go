@@ -153,7 +153,3 @@ func (h Hosts) String() string {
 func (h Host) String() string {
 	return fmt.Sprint(h.Data)
 }
-
-func ref[T any](x T) *T {
-	return &x
-}
@@ -18,6 +18,18 @@ func (u WithBaseURL) Apply(o *option.Options) {
 	o.BaseURL = string(u)
 }
 
+type WithSourceFilter string
+
+func (s WithSourceFilter) Apply(o *option.Options) {
+	o.SourceFilter = string(s)
+}
+
+type WithInclude string
+
+func (i WithInclude) Apply(o *option.Options) {
+	o.Include = string(i)
+}
+
 func TestHostsDataStringer(t *testing.T) {
 	tests := []struct {
 		name string
@@ -120,3 +132,7 @@ func TestHostsStringer(t *testing.T) {
 		})
 	}
 }
+
+func ref[T any](x T) *T {
+	return &x
+}
@@ -1,40 +1,32 @@
 package host
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"net/http"
 
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/resource"
 	"github.com/mikelorant/easyredir/pkg/easyredir/option"
-	"github.com/mikelorant/easyredir/pkg/jsonutil"
 )
 
 func UpdateHost(cl ClientAPI, id string, attr Attributes, opts ...option.Option) (h Host, err error) {
-	h = Host{}
-
-	o := &option.Options{}
-	for _, opt := range opts {
-		opt.Apply(o)
-	}
-
-	var b bytes.Buffer
-	if err := jsonutil.EncodeJSON(&attr, &b); err != nil {
-		return h, fmt.Errorf("unable to encode to json: %w", err)
-	}
+	return UpdateHostWithContext(context.Background(), cl, id, attr, opts...)
+}
 
-	pathQuery := buildUpdateHost(id)
-	reader, err := cl.SendRequest(pathQuery, http.MethodPatch, &b)
+func UpdateHostWithContext(ctx context.Context, cl ClientAPI, id string, attr Attributes, opts ...option.Option) (h Host, err error) {
+	o, err := option.New(option.FieldSkipValidation, opts...)
 	if err != nil {
-		return h, fmt.Errorf("unable to send request: %w", err)
+		return h, fmt.Errorf("unable to apply options: %w", err)
 	}
 
-	if err := jsonutil.DecodeJSON(reader, &h); err != nil {
-		return h, fmt.Errorf("unable to get json: %w", err)
+	if !o.SkipValidation {
+		if err := attr.Validate(); err != nil {
+			return h, fmt.Errorf("invalid host: %w", err)
+		}
 	}
 
-	return h, nil
+	return resource.Update[Host](ctx, cl, buildUpdateHost(id), &attr)
 }
 
 func buildUpdateHost(id string) string {
-	return fmt.Sprintf("/hosts/%v", id)
+	return resource.NewQuery("hosts", id).String()
 }
@@ -0,0 +1,67 @@
+package host
+
+import (
+	"fmt"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+)
+
+// HSTSPreloadMinMaxAge is the minimum HSTS max-age in seconds for preloading.
+const HSTSPreloadMinMaxAge = 31536000
+
+// Validate checks the attributes that are set.
+func (a Attributes) Validate() error {
+	var errs []client.APIError
+
+	add := func(param string, code client.ErrorCode, format string, v ...any) {
+		errs = append(errs, client.APIError{
+			Resource: "host",
+			Param:    param,
+			Code:     code,
+			Message:  fmt.Sprintf(format, v...),
+		})
+	}
+
+	s := a.Security
+
+	if s.HSTSMaxAge != nil && *s.HSTSMaxAge < 0 {
+		add("security.hsts_max_age", client.ErrorCodeInvalid, "HSTS max age must not be negative: %v", *s.HSTSMaxAge)
+	}
+
+	if s.HSTSPreload != nil && *s.HSTSPreload {
+		if s.HSTSIncludeSubDomains == nil || !*s.HSTSIncludeSubDomains {
+			add("security.hsts_include_sub_domains", client.ErrorCodeRequired, "HSTS preload requires include sub domains")
+		}
+
+		switch {
+		case s.HSTSMaxAge == nil:
+			add("security.hsts_max_age", client.ErrorCodeRequired, "HSTS preload requires a max age of at least %v", HSTSPreloadMinMaxAge)
+		case *s.HSTSMaxAge >= 0 && *s.HSTSMaxAge < HSTSPreloadMinMaxAge:
+			add("security.hsts_max_age", client.ErrorCodeInvalid, "HSTS preload requires a max age of at least %v: %v", HSTSPreloadMinMaxAge, *s.HSTSMaxAge)
+		}
+	}
+
+	n := a.NotFoundAction
+
+	if n.ResponseCode != nil {
+		switch *n.ResponseCode {
+		case ResponseCodeMovedPermanently, ResponseCodeFound:
+		case ResponseCodeNotFound:
+			if n.ResponseURL != nil && *n.ResponseURL != "" {
+				add("not_found_action.response_url", client.ErrorCodeInvalid, "response URL must not be set with response code %v", *n.ResponseCode)
+			}
+		default:
+			add("not_found_action.response_code", client.ErrorCodeInvalidOption, "response code must be 301, 302 or 404: %v", *n.ResponseCode)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return client.APIErrors{
+		Type:    client.ErrorTypeInvalidRequest,
+		Message: "Invalid host",
+		Errors:  errs,
+	}
+}
@@ -0,0 +1,165 @@
+package host
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/stretchr/testify/assert"
+)
+
+type WithValidation bool
+
+func (v WithValidation) Apply(o *option.Options) {
+	o.SkipValidation = !bool(v)
+}
+
+func TestAttributesValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		give Attributes
+		want []client.APIError
+	}{
+		{
+			name: "empty",
+			give: Attributes{},
+		},
+		{
+			name: "valid",
+			give: Attributes{
+				NotFoundAction: NotFoundAction{
+					ResponseCode: ref(ResponseCodeNotFound),
+				},
+				Security: Security{
+					HSTSIncludeSubDomains: ptr.Bool(true),
+					HSTSMaxAge:            ptr.Int(63072000),
+					HSTSPreload:           ptr.Bool(true),
+				},
+			},
+		},
+		{
+			name: "preload_without_sub_domains",
+			give: Attributes{
+				Security: Security{
+					HSTSIncludeSubDomains: ptr.Bool(false),
+					HSTSMaxAge:            ptr.Int(31536000),
+					HSTSPreload:           ptr.Bool(true),
+				},
+			},
+			want: []client.APIError{
+				{Resource: "host", Param: "security.hsts_include_sub_domains", Code: client.ErrorCodeRequired, Message: "HSTS preload requires include sub domains"},
+			},
+		},
+		{
+			name: "preload_max_age_too_low",
+			give: Attributes{
+				Security: Security{
+					HSTSIncludeSubDomains: ptr.Bool(true),
+					HSTSMaxAge:            ptr.Int(86400),
+					HSTSPreload:           ptr.Bool(true),
+				},
+			},
+			want: []client.APIError{
+				{Resource: "host", Param: "security.hsts_max_age", Code: client.ErrorCodeInvalid, Message: "HSTS preload requires a max age of at least 31536000: 86400"},
+			},
+		},
+		{
+			name: "preload_max_age_missing",
+			give: Attributes{
+				Security: Security{
+					HSTSIncludeSubDomains: ptr.Bool(true),
+					HSTSPreload:           ptr.Bool(true),
+				},
+			},
+			want: []client.APIError{
+				{Resource: "host", Param: "security.hsts_max_age", Code: client.ErrorCodeRequired, Message: "HSTS preload requires a max age of at least 31536000"},
+			},
+		},
+		{
+			name: "negative_max_age",
+			give: Attributes{
+				Security: Security{
+					HSTSMaxAge: ptr.Int(-1),
+				},
+			},
+			want: []client.APIError{
+				{Resource: "host", Param: "security.hsts_max_age", Code: client.ErrorCodeInvalid, Message: "HSTS max age must not be negative: -1"},
+			},
+		},
+		{
+			name: "unknown_response_code",
+			give: Attributes{
+				NotFoundAction: NotFoundAction{
+					ResponseCode: ref(ResponseCode(307)),
+				},
+			},
+			want: []client.APIError{
+				{Resource: "host", Param: "not_found_action.response_code", Code: client.ErrorCodeInvalidOption, Message: "response code must be 301, 302 or 404: 307"},
+			},
+		},
+		{
+			name: "response_url_with_not_found",
+			give: Attributes{
+				NotFoundAction: NotFoundAction{
+					ResponseCode: ref(ResponseCodeNotFound),
+					ResponseURL:  ptr.String("https://www.example.com"),
+				},
+			},
+			want: []client.APIError{
+				{Resource: "host", Param: "not_found_action.response_url", Code: client.ErrorCodeInvalid, Message: "response URL must not be set with response code 404"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.give.Validate()
+			if tt.want == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			var apiErrs client.APIErrors
+			td.CmpTrue(t, errors.As(err, &apiErrs))
+			td.CmpTrue(t, errors.Is(err, client.ErrValidation))
+			td.Cmp(t, apiErrs.Errors, tt.want)
+		})
+	}
+}
+
+func TestHostValidation(t *testing.T) {
+	var requests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data": {"id": "abc-def", "type": "host"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	attr := Attributes{
+		Security: Security{
+			HSTSPreload: ptr.Bool(true),
+		},
+	}
+
+	_, err := UpdateHost(cl, "abc-def", attr)
+	td.CmpTrue(t, errors.Is(err, client.ErrValidation))
+	td.CmpContains(t, err, "invalid host")
+
+	td.Cmp(t, requests, 0)
+
+	_, err = UpdateHost(cl, "abc-def", attr, WithValidation(false))
+	assert.Nil(t, err)
+
+	td.Cmp(t, requests, 1)
+}
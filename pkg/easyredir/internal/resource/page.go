@@ -0,0 +1,141 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+)
+
+type Page[T any] struct {
+	Data     []T             `json:"data"`
+	Metadata option.Metadata `json:"meta"`
+	Links    option.Links    `json:"links"`
+}
+
+type ListFunc[T any] func(ctx context.Context, opts ...option.Option) (Page[T], error)
+
+type Iterator[T any] struct {
+	ctx      context.Context
+	name     string
+	list     ListFunc[T]
+	id       func(T) string
+	opts     []option.Option
+	page     Page[T]
+	idx      int
+	item     T
+	err      error
+	fetched  bool
+	backward bool
+	cursor   option.Pagination
+}
+
+func (p Page[T]) HasMore() bool {
+	return p.Metadata.HasMore
+}
+
+// NewIterator walks the pages returned by list. The name is used in errors
+// and id returns the cursor for an item.
+func NewIterator[T any](ctx context.Context, name string, list ListFunc[T], id func(T) string, opts ...option.Option) *Iterator[T] {
+	o := &option.Options{}
+	for _, opt := range opts {
+		opt.Apply(o)
+	}
+
+	return &Iterator[T]{
+		ctx:      ctx,
+		name:     name,
+		list:     list,
+		id:       id,
+		opts:     opts,
+		backward: o.Pagination.EndingBefore != "",
+	}
+}
+
+func Paginate[T any](it *Iterator[T]) ([]T, error) {
+	items := []T{}
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+
+	return items, it.Err()
+}
+
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for it.idx >= len(it.page.Data) {
+		if it.fetched && !it.page.HasMore() {
+			return false
+		}
+
+		if err := it.ctx.Err(); err != nil {
+			it.err = fmt.Errorf("unable to get a %v page: %w", it.name, err)
+			return false
+		}
+
+		opts := it.opts
+		if it.fetched {
+			page, err := it.nextPage()
+			if err != nil {
+				it.err = fmt.Errorf("unable to get a %v page: %w", it.name, err)
+				return false
+			}
+			opts = append(opts[:len(opts):len(opts)], page)
+		}
+
+		page, err := it.list(it.ctx, opts...)
+		if err != nil {
+			it.err = fmt.Errorf("unable to get a %v page: %w", it.name, err)
+			return false
+		}
+
+		it.page = page
+		it.idx = 0
+		it.fetched = true
+	}
+
+	if it.backward {
+		it.item = it.page.Data[len(it.page.Data)-1-it.idx]
+		it.cursor = option.Pagination{EndingBefore: it.id(it.item)}
+	} else {
+		it.item = it.page.Data[it.idx]
+		it.cursor = option.Pagination{StartingAfter: it.id(it.item)}
+	}
+	it.idx++
+
+	return true
+}
+
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Cursor returns the position after the current item. Passing it as an
+// option to a new iterator resumes the walk in the same direction.
+func (it *Iterator[T]) Cursor() option.Pagination {
+	return it.cursor
+}
+
+func (it *Iterator[T]) nextPage() (option.Option, error) {
+	if it.backward {
+		c, err := it.page.Links.PrevCursor()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get previous page: %w", err)
+		}
+		return option.Pagination{EndingBefore: c}, nil
+	}
+
+	c, err := it.page.Links.NextCursor()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get next page: %w", err)
+	}
+
+	return option.Pagination{StartingAfter: c}, nil
+}
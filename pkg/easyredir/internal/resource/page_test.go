@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaginate(t *testing.T) {
+	type Fields struct {
+		pages map[string]Page[string]
+		err   error
+	}
+
+	type Want struct {
+		items []string
+		err   string
+	}
+
+	tests := []struct {
+		name   string
+		fields Fields
+		want   Want
+	}{
+		{
+			name: "many",
+			fields: Fields{
+				pages: map[string]Page[string]{
+					"": {
+						Data:     []string{"a", "b"},
+						Metadata: option.Metadata{HasMore: true},
+						Links:    option.Links{Next: "/v1/items?starting_after=b"},
+					},
+					"b": {
+						Data: []string{"c"},
+					},
+				},
+			},
+			want: Want{
+				items: []string{"a", "b", "c"},
+			},
+		},
+		{
+			name: "none",
+			fields: Fields{
+				pages: map[string]Page[string]{
+					"": {},
+				},
+			},
+			want: Want{
+				items: []string{},
+			},
+		},
+		{
+			name: "error",
+			fields: Fields{
+				err: errors.New("boom"),
+			},
+			want: Want{
+				items: []string{},
+				err:   "unable to get a items page: boom",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := func(ctx context.Context, opts ...option.Option) (Page[string], error) {
+				o := &option.Options{}
+				for _, opt := range opts {
+					opt.Apply(o)
+				}
+
+				return tt.fields.pages[o.Pagination.StartingAfter], tt.fields.err
+			}
+			id := func(s string) string {
+				return s
+			}
+
+			got, err := Paginate(NewIterator(context.Background(), "items", list, id))
+			td.Cmp(t, got, tt.want.items)
+			if tt.want.err != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
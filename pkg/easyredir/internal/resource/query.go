@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type Query struct {
+	path   string
+	values url.Values
+}
+
+// NewQuery builds a path from the segments, escaping each one so that IDs
+// can never change the path or start a query.
+func NewQuery(segments ...string) *Query {
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = url.PathEscape(s)
+	}
+
+	return &Query{
+		path:   "/" + strings.Join(escaped, "/"),
+		values: url.Values{},
+	}
+}
+
+// Add appends the parameter unless the value is empty.
+func (q *Query) Add(key, value string) *Query {
+	if value != "" {
+		q.values.Add(key, value)
+	}
+
+	return q
+}
+
+// AddInt appends the parameter unless the value is zero.
+func (q *Query) AddInt(key string, value int) *Query {
+	if value != 0 {
+		q.values.Add(key, strconv.Itoa(value))
+	}
+
+	return q
+}
+
+func (q *Query) String() string {
+	if len(q.values) == 0 {
+		return q.path
+	}
+
+	return q.path + "?" + q.values.Encode()
+}
@@ -0,0 +1,68 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+)
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		give *Query
+		want string
+	}{
+		{
+			name: "path",
+			give: NewQuery("rules"),
+			want: "/rules",
+		},
+		{
+			name: "id",
+			give: NewQuery("rules", "abc-def"),
+			want: "/rules/abc-def",
+		},
+		{
+			name: "params",
+			give: NewQuery("rules").
+				Add("starting_after", "abc-def").
+				AddInt("limit", 10),
+			want: "/rules?limit=10&starting_after=abc-def",
+		},
+		{
+			name: "empty_params",
+			give: NewQuery("rules").
+				Add("sq", "").
+				AddInt("limit", 0),
+			want: "/rules",
+		},
+		{
+			name: "hostile_id",
+			give: NewQuery("rules", "../hosts/abc?x=1#frag"),
+			want: "/rules/..%2Fhosts%2Fabc%3Fx=1%23frag",
+		},
+		{
+			name: "hostile_id_space",
+			give: NewQuery("hosts", "abc def%"),
+			want: "/hosts/abc%20def%25",
+		},
+		{
+			name: "hostile_value",
+			give: NewQuery("rules").
+				Add("sq", "http://example.com/a b?x=1&y=2#top"),
+			want: "/rules?sq=http%3A%2F%2Fexample.com%2Fa+b%3Fx%3D1%26y%3D2%23top",
+		},
+		{
+			name: "array_key",
+			give: NewQuery("rules").
+				Add("include[]", "source_hosts"),
+			want: "/rules?include%5B%5D=source_hosts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, tt.give.String(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mikelorant/easyredir/pkg/jsonutil"
+)
+
+type ClientAPI interface {
+	SendRequest(path, method string, body io.Reader) (io.ReadCloser, error)
+}
+
+// contextClientAPI is implemented by clients that can cancel a request with
+// its context. Other clients send the request without it.
+type contextClientAPI interface {
+	SendRequestWithContext(ctx context.Context, path, method string, body io.Reader) (io.ReadCloser, error)
+}
+
+func Get[T any](ctx context.Context, cl ClientAPI, pathQuery string) (v T, err error) {
+	return send[T](ctx, cl, pathQuery, http.MethodGet, nil)
+}
+
+func List[T any](ctx context.Context, cl ClientAPI, pathQuery string) (p Page[T], err error) {
+	return send[Page[T]](ctx, cl, pathQuery, http.MethodGet, nil)
+}
+
+func Create[T any](ctx context.Context, cl ClientAPI, pathQuery string, body any) (v T, err error) {
+	return send[T](ctx, cl, pathQuery, http.MethodPost, body)
+}
+
+func Update[T any](ctx context.Context, cl ClientAPI, pathQuery string, body any) (v T, err error) {
+	return send[T](ctx, cl, pathQuery, http.MethodPatch, body)
+}
+
+func Remove(ctx context.Context, cl ClientAPI, pathQuery string) error {
+	reader, err := sendRequest(ctx, cl, pathQuery, http.MethodDelete, nil)
+	if err != nil {
+		return fmt.Errorf("unable to send request: %w", err)
+	}
+	reader.Close()
+
+	return nil
+}
+
+func send[T any](ctx context.Context, cl ClientAPI, pathQuery, method string, body any) (v T, err error) {
+	var r io.Reader
+	if body != nil {
+		var b bytes.Buffer
+		if err := jsonutil.EncodeJSON(body, &b); err != nil {
+			return v, fmt.Errorf("unable to encode to json: %w", err)
+		}
+		r = &b
+	}
+
+	reader, err := sendRequest(ctx, cl, pathQuery, method, r)
+	if err != nil {
+		return v, fmt.Errorf("unable to send request: %w", err)
+	}
+
+	if err := jsonutil.DecodeJSON(reader, &v); err != nil {
+		return v, fmt.Errorf("unable to get json: %w", err)
+	}
+
+	return v, nil
+}
+
+func sendRequest(ctx context.Context, cl ClientAPI, pathQuery, method string, body io.Reader) (io.ReadCloser, error) {
+	if c, ok := cl.(contextClientAPI); ok {
+		return c.SendRequestWithContext(ctx, pathQuery, method, body)
+	}
+
+	return cl.SendRequest(pathQuery, method, body)
+}
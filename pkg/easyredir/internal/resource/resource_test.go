@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockClient struct {
+	calls *[]string
+}
+
+func (m mockClient) SendRequest(path, method string, body io.Reader) (io.ReadCloser, error) {
+	*m.calls = append(*m.calls, "SendRequest "+method+" "+path)
+	return io.NopCloser(strings.NewReader(`{"id": "abc"}`)), nil
+}
+
+type mockContextClient struct {
+	mockClient
+}
+
+func (m mockContextClient) SendRequestWithContext(ctx context.Context, path, method string, body io.Reader) (io.ReadCloser, error) {
+	*m.calls = append(*m.calls, "SendRequestWithContext "+method+" "+path)
+	return io.NopCloser(strings.NewReader(`{"id": "abc"}`)), nil
+}
+
+func TestSendRequest(t *testing.T) {
+	type Data struct {
+		ID string `json:"id"`
+	}
+
+	tests := []struct {
+		name string
+		give func(calls *[]string) ClientAPI
+		want []string
+	}{
+		{
+			name: "without_context",
+			give: func(calls *[]string) ClientAPI {
+				return mockClient{calls: calls}
+			},
+			want: []string{"SendRequest GET /rules/abc"},
+		},
+		{
+			name: "with_context",
+			give: func(calls *[]string) ClientAPI {
+				return mockContextClient{mockClient{calls: calls}}
+			},
+			want: []string{"SendRequestWithContext GET /rules/abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+
+			got, err := Get[Data](context.Background(), tt.give(&calls), "/rules/abc")
+			assert.Nil(t, err)
+			td.Cmp(t, got.ID, "abc")
+			td.Cmp(t, calls, tt.want)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package sourceurl
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+)
+
+// Parse accepts URLs with or without a scheme, as source URLs are usually
+// written, and reports whether one was given.
+func Parse(s string) (u *url.URL, scheme bool, err error) {
+	s = strings.TrimSpace(s)
+
+	scheme = strings.Contains(s, "://")
+	if !scheme {
+		s = "http://" + s
+	}
+
+	u, err = url.Parse(s)
+
+	return u, scheme, err
+}
+
+// Hostname returns the lower case host name of the URL, or an empty string
+// when it cannot be parsed.
+func Hostname(s string) string {
+	u, _, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// Normalize returns the form of a source URL used for matching under the
+// host's match options. The scheme is only kept when one was given.
+func Normalize(s string, mo host.MatchOptions) string {
+	u, scheme, err := Parse(s)
+	if err != nil {
+		return s
+	}
+
+	k := strings.ToLower(u.Host) + NormalizePath(u.Path, mo)
+	if u.RawQuery != "" {
+		k += "?" + u.Query().Encode()
+	}
+	if scheme {
+		k = strings.ToLower(u.Scheme) + "://" + k
+	}
+
+	return k
+}
+
+// Keys returns the normalised source under each scheme it matches. A source
+// without a scheme matches both http and https.
+func Keys(s string, mo host.MatchOptions) []string {
+	k := Normalize(s, mo)
+
+	if _, scheme, err := Parse(s); err != nil || scheme {
+		return []string{k}
+	}
+
+	return []string{"http://" + k, "https://" + k}
+}
+
+// NormalizePath applies the match options to a path. The root path is always
+// "/".
+func NormalizePath(p string, mo host.MatchOptions) string {
+	if mo.CaseInsensitive != nil && *mo.CaseInsensitive {
+		p = strings.ToLower(p)
+	}
+	if mo.SlashInsensitive != nil && *mo.SlashInsensitive {
+		p = strings.TrimRight(p, "/")
+	}
+	if p == "" {
+		p = "/"
+	}
+
+	return p
+}
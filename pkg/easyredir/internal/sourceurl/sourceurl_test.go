@@ -0,0 +1,72 @@
+package sourceurl
+
+import (
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+)
+
+func TestHostname(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{name: "bare", give: "ABC.com/path", want: "abc.com"},
+		{name: "scheme", give: " https://abc.com:8080/path", want: "abc.com"},
+		{name: "invalid", give: "abc.com/%zz", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, Hostname(tt.give), tt.want)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	insensitive := host.MatchOptions{
+		CaseInsensitive:  ptr.Bool(true),
+		SlashInsensitive: ptr.Bool(true),
+	}
+
+	tests := []struct {
+		name string
+		give string
+		opts host.MatchOptions
+		want string
+	}{
+		{name: "root", give: "ABC.com", want: "abc.com/"},
+		{name: "trailing_root", give: "abc.com/", want: "abc.com/"},
+		{name: "sensitive", give: "abc.com/Path/", want: "abc.com/Path/"},
+		{name: "insensitive", give: "abc.com/Path/", opts: insensitive, want: "abc.com/path"},
+		{name: "scheme", give: "HTTPS://abc.com/a?b=1&a=2", want: "https://abc.com/a?a=2&b=1"},
+		{name: "space", give: " abc.com/path ", want: "abc.com/path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, Normalize(tt.give, tt.opts), tt.want)
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want []string
+	}{
+		{name: "no_scheme", give: "ABC.com/a", want: []string{"http://abc.com/a", "https://abc.com/a"}},
+		{name: "http", give: "http://abc.com/a", want: []string{"http://abc.com/a"}},
+		{name: "https", give: "HTTPS://abc.com/a", want: []string{"https://abc.com/a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, Keys(tt.give, host.MatchOptions{}), tt.want)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/sourceurl"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+type claim struct {
+	rule   rule.Data
+	source string
+}
+
+// Duplicates groups source URLs that the host would treat as the same, using
+// its case and slash match options. A source without a scheme matches both
+// http and https, so it is grouped with sources for either scheme. A group is
+// reported as a duplicate when the sources are written identically and as a
+// collision otherwise. When the rules in a group have different targets a
+// conflict is reported as well.
+func Duplicates(r rule.Rules, h host.Hosts) []Finding {
+	opts := make(map[string]host.MatchOptions)
+	for _, d := range h.Data {
+		opts[strings.ToLower(d.Attributes.Name)] = d.Attributes.MatchOptions
+	}
+
+	var claims []claim
+	for _, d := range r.Data {
+		for _, src := range d.Attributes.SourceURLs {
+			claims = append(claims, claim{rule: d, source: src})
+		}
+	}
+
+	// Claims sharing a key are joined, keeping the earliest claim as the root.
+	parent := make([]int, len(claims))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+
+	first := make(map[string]int)
+	for i, c := range claims {
+		for _, k := range sourceurl.Keys(c.source, opts[sourceurl.Hostname(c.source)]) {
+			j, ok := first[k]
+			if !ok {
+				first[k] = i
+				continue
+			}
+
+			ri, rj := root(i), root(j)
+			parent[max(ri, rj)] = min(ri, rj)
+		}
+	}
+
+	var order []int
+	groups := make(map[int][]claim)
+
+	for i, c := range claims {
+		ri := root(i)
+		if _, ok := groups[ri]; !ok {
+			order = append(order, ri)
+		}
+		groups[ri] = append(groups[ri], c)
+	}
+
+	var fs []Finding
+
+	for _, ri := range order {
+		cs := groups[ri]
+		if len(cs) < 2 {
+			continue
+		}
+
+		f := Finding{Kind: KindCollision}
+		if identical(cs) {
+			f.Kind = KindDuplicate
+		}
+
+		for _, c := range cs {
+			if !slices.Contains(f.RuleIDs, c.rule.ID) {
+				f.RuleIDs = append(f.RuleIDs, c.rule.ID)
+			}
+			f.URLs = append(f.URLs, c.source)
+		}
+
+		if !sameTargets(cs) {
+			fs = append(fs, f, conflict(cs))
+			continue
+		}
+
+		f.Suggestion = suggest(cs)
+		fs = append(fs, f)
+	}
+
+	return fs
+}
+
+func identical(cs []claim) bool {
+	for _, c := range cs[1:] {
+		if strings.TrimSpace(c.source) != strings.TrimSpace(cs[0].source) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sameTargets(cs []claim) bool {
+	for _, c := range cs[1:] {
+		if target(c.rule) != target(cs[0].rule) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func target(d rule.Data) string {
+	if d.Attributes.TargetURL == nil {
+		return ""
+	}
+
+	return sourceurl.Normalize(*d.Attributes.TargetURL, host.MatchOptions{})
+}
+
+func conflict(cs []claim) Finding {
+	f := Finding{Kind: KindConflict}
+
+	for _, c := range cs {
+		if slices.Contains(f.RuleIDs, c.rule.ID) {
+			continue
+		}
+		f.RuleIDs = append(f.RuleIDs, c.rule.ID)
+
+		t := ""
+		if c.rule.Attributes.TargetURL != nil {
+			t = *c.rule.Attributes.TargetURL
+		}
+		f.URLs = append(f.URLs, fmt.Sprintf("%v => %v", c.source, t))
+	}
+
+	return f
+}
+
+// suggest keeps the first claim and removes the others, either the whole rule
+// when the source is all it has or only the source.
+func suggest(cs []claim) string {
+	var s []string
+
+	for _, c := range cs[1:] {
+		switch {
+		case len(c.rule.Attributes.SourceURLs) == 1:
+			s = append(s, fmt.Sprintf("remove rule %v", c.rule.ID))
+		default:
+			s = append(s, fmt.Sprintf("remove %v from rule %v", c.source, c.rule.ID))
+		}
+	}
+
+	return strings.Join(s, "; ")
+}
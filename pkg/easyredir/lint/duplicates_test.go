@@ -0,0 +1,179 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+func TestDuplicates(t *testing.T) {
+	insensitive := []host.Data{
+		{
+			Attributes: host.Attributes{
+				Name: "abc.com",
+				MatchOptions: host.MatchOptions{
+					CaseInsensitive:  ptr.Bool(true),
+					SlashInsensitive: ptr.Bool(true),
+				},
+			},
+		},
+	}
+
+	type Args struct {
+		rules []rule.Data
+		hosts []host.Data
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want []Finding
+	}{
+		{
+			name: "clean",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "abc.com/a"),
+					newRule("rule-2", "https://new.com", false, "abc.com/b"),
+				},
+			},
+		},
+		{
+			name: "duplicate",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "abc.com/a", "abc.com/b"),
+					newRule("rule-2", "https://new.com/", false, "abc.com/a"),
+				},
+			},
+			want: []Finding{
+				{
+					Kind:       KindDuplicate,
+					RuleIDs:    []string{"rule-1", "rule-2"},
+					URLs:       []string{"abc.com/a", "abc.com/a"},
+					Suggestion: "remove rule rule-2",
+				},
+			},
+		},
+		{
+			name: "sensitive_host",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "abc.com/a"),
+					newRule("rule-2", "https://new.com", false, "abc.com/A", "abc.com/a/"),
+				},
+			},
+		},
+		{
+			name: "collision",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "abc.com/a"),
+					newRule("rule-2", "https://new.com", false, "ABC.com/A/", "abc.com/b"),
+				},
+				hosts: insensitive,
+			},
+			want: []Finding{
+				{
+					Kind:       KindCollision,
+					RuleIDs:    []string{"rule-1", "rule-2"},
+					URLs:       []string{"abc.com/a", "ABC.com/A/"},
+					Suggestion: "remove ABC.com/A/ from rule rule-2",
+				},
+			},
+		},
+		{
+			name: "within_rule",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "abc.com/a", "abc.com/a/"),
+				},
+				hosts: insensitive,
+			},
+			want: []Finding{
+				{
+					Kind:       KindCollision,
+					RuleIDs:    []string{"rule-1"},
+					URLs:       []string{"abc.com/a", "abc.com/a/"},
+					Suggestion: "remove abc.com/a/ from rule rule-1",
+				},
+			},
+		},
+		{
+			name: "mixed_schemes",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "http://abc.com/a"),
+					newRule("rule-2", "https://new.com", false, "abc.com/a"),
+					newRule("rule-3", "https://new.com", false, "https://abc.com/A"),
+				},
+				hosts: insensitive,
+			},
+			want: []Finding{
+				{
+					Kind:       KindCollision,
+					RuleIDs:    []string{"rule-1", "rule-2", "rule-3"},
+					URLs:       []string{"http://abc.com/a", "abc.com/a", "https://abc.com/A"},
+					Suggestion: "remove rule rule-2; remove rule rule-3",
+				},
+			},
+		},
+		{
+			name: "distinct_schemes",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "http://abc.com/a"),
+					newRule("rule-2", "https://new.com", false, "https://abc.com/a"),
+				},
+			},
+		},
+		{
+			name: "conflict",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "abc.com/a"),
+					newRule("rule-2", "https://other.com", false, "abc.com/A"),
+				},
+				hosts: insensitive,
+			},
+			want: []Finding{
+				{
+					Kind:    KindCollision,
+					RuleIDs: []string{"rule-1", "rule-2"},
+					URLs:    []string{"abc.com/a", "abc.com/A"},
+				},
+				{
+					Kind:    KindConflict,
+					RuleIDs: []string{"rule-1", "rule-2"},
+					URLs:    []string{"abc.com/a => https://new.com", "abc.com/A => https://other.com"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Duplicates(rule.Rules{Data: tt.args.rules}, host.Hosts{Data: tt.args.hosts})
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestDuplicatesFormat(t *testing.T) {
+	got := Duplicates(rule.Rules{Data: []rule.Data{
+		newRule("rule-1", "https://new.com", false, "abc.com/a"),
+		newRule("rule-2", "https://new.com", false, "abc.com/a"),
+		newRule("rule-3", "https://other.com", false, "abc.com/a"),
+	}}, host.Hosts{})
+
+	td.Cmp(t, Format(got), heredoc.Doc(`
+		duplicate: rule-1, rule-2, rule-3: abc.com/a, abc.com/a, abc.com/a
+		conflict: rule-1, rule-2, rule-3: abc.com/a => https://new.com, abc.com/a => https://new.com, abc.com/a => https://other.com
+
+		2 problems found.
+	`))
+}
@@ -0,0 +1,73 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Kind string
+
+const (
+	KindSelfRedirect Kind = "self-redirect"
+	KindLoop         Kind = "loop"
+	KindChain        Kind = "chain"
+	KindDuplicate    Kind = "duplicate"
+	KindCollision    Kind = "collision"
+	KindConflict     Kind = "conflict"
+)
+
+// Finding is a problem with one or more rules. For redirect problems URLs
+// holds the path a request takes, starting at a source URL, otherwise it holds
+// the sources involved.
+type Finding struct {
+	Kind       Kind
+	RuleIDs    []string
+	URLs       []string
+	Suggestion string
+}
+
+const (
+	NoFindings = "No problems found."
+)
+
+func (f Finding) String() string {
+	var b strings.Builder
+
+	switch f.Kind {
+	case KindChain:
+		fmt.Fprintf(&b, "%v of %v hops", f.Kind, len(f.URLs)-1)
+	default:
+		fmt.Fprint(&b, f.Kind)
+	}
+
+	switch f.Kind {
+	case KindSelfRedirect, KindLoop, KindChain:
+		fmt.Fprintf(&b, ": %v: %v", strings.Join(f.RuleIDs, " -> "), strings.Join(f.URLs, " -> "))
+	default:
+		fmt.Fprintf(&b, ": %v: %v", strings.Join(f.RuleIDs, ", "), strings.Join(f.URLs, ", "))
+	}
+
+	if f.Suggestion != "" {
+		fmt.Fprintf(&b, "\n    suggestion: %v", f.Suggestion)
+	}
+
+	return b.String()
+}
+
+func Format(fs []Finding) string {
+	if len(fs) == 0 {
+		return NoFindings + "\n"
+	}
+
+	var b strings.Builder
+	for _, f := range fs {
+		fmt.Fprintln(&b, f)
+	}
+	if len(fs) == 1 {
+		fmt.Fprintln(&b, "\n1 problem found.")
+	} else {
+		fmt.Fprintf(&b, "\n%v problems found.\n", len(fs))
+	}
+
+	return b.String()
+}
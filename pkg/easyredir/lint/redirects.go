@@ -0,0 +1,124 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/sourceurl"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+	"github.com/mikelorant/easyredir/pkg/easyredir/simulator"
+)
+
+// DefaultMaxHops allows a single redirect, anything longer is a chain.
+const DefaultMaxHops = 1
+
+// maxFollow bounds how far a chain is followed.
+const maxFollow = 32
+
+// Redirects follows every source URL through the rules, forwarding paths the
+// way the service would. It reports rules that redirect to themselves, loops
+// between rules and chains of more than maxHops redirects. A chain comes with
+// the final target so the first rule can point there directly, unless a path
+// or query was forwarded on the way as the target would then depend on the
+// request.
+func Redirects(r rule.Rules, h host.Hosts, maxHops int) []Finding {
+	if maxHops < 1 {
+		maxHops = DefaultMaxHops
+	}
+
+	sim := simulator.New(r, h)
+
+	var fs []Finding
+	seen := make(map[string]bool)
+
+	for _, d := range r.Data {
+		for _, src := range d.Attributes.SourceURLs {
+			f, ok := follow(sim, src, maxHops)
+			if !ok {
+				continue
+			}
+
+			k := key(f)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+
+			fs = append(fs, f)
+		}
+	}
+
+	return fs
+}
+
+func follow(sim *simulator.Simulator, src string, maxHops int) (f Finding, ok bool) {
+	urls := []string{src}
+	var ids []string
+	var fwd bool
+
+	next := src
+	for n := 0; n < maxFollow; n++ {
+		res, err := sim.Resolve(next)
+		if err != nil || res.Rule == nil || res.Location == "" {
+			break
+		}
+
+		id := res.Rule.ID
+		if i := slices.Index(ids, id); i != -1 {
+			ids = append(ids, id)
+
+			if len(ids)-i == 2 {
+				return Finding{Kind: KindSelfRedirect, RuleIDs: ids[i:], URLs: urls[i:]}, true
+			}
+
+			return Finding{Kind: KindLoop, RuleIDs: ids[i:], URLs: urls[i:]}, true
+		}
+
+		ids = append(ids, id)
+		urls = append(urls, res.Location)
+		next = res.Location
+		fwd = fwd || forwarded(res)
+	}
+
+	if len(ids) > maxHops {
+		f = Finding{Kind: KindChain, RuleIDs: ids, URLs: urls}
+		if !fwd {
+			f.Suggestion = fmt.Sprintf("set the target of %v to %v", ids[0], urls[len(urls)-1])
+		}
+
+		return f, true
+	}
+
+	return f, false
+}
+
+// forwarded reports whether the location has more than the target of the
+// rule, from a forwarded path or query.
+func forwarded(res simulator.Result) bool {
+	if res.Rule.Attributes.TargetURL == nil {
+		return false
+	}
+
+	t, _, err := sourceurl.Parse(*res.Rule.Attributes.TargetURL)
+	if err != nil {
+		return false
+	}
+
+	return res.Location != t.String()
+}
+
+// key identifies a finding so that a loop is reported once no matter which
+// of its sources it was found from.
+func key(f Finding) string {
+	switch f.Kind {
+	case KindChain:
+		return string(f.Kind) + " " + f.URLs[0]
+	default:
+		ids := slices.Clone(f.RuleIDs[:len(f.RuleIDs)-1])
+		sort.Strings(ids)
+		return string(f.Kind) + " " + strings.Join(ids, " ")
+	}
+}
@@ -0,0 +1,149 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+func newRule(id, target string, forwardPath bool, sources ...string) rule.Data {
+	return rule.Data{
+		ID: id,
+		Attributes: rule.Attributes{
+			ForwardPath: ptr.Bool(forwardPath),
+			SourceURLs:  sources,
+			TargetURL:   ptr.String(target),
+		},
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	type Args struct {
+		rules   []rule.Data
+		hosts   []host.Data
+		maxHops int
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want []Finding
+	}{
+		{
+			name: "clean",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://new.com", false, "abc.com/a", "abc.com/b"),
+					newRule("rule-2", "https://new.com/c", false, "xyz.com"),
+				},
+			},
+		},
+		{
+			name: "self_redirect",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "http://abc.com/a/", false, "abc.com/a"),
+				},
+				hosts: []host.Data{
+					{Attributes: host.Attributes{Name: "abc.com", MatchOptions: host.MatchOptions{SlashInsensitive: ptr.Bool(true)}}},
+				},
+			},
+			want: []Finding{
+				{Kind: KindSelfRedirect, RuleIDs: []string{"rule-1", "rule-1"}, URLs: []string{"abc.com/a", "http://abc.com/a/"}},
+			},
+		},
+		{
+			name: "self_redirect_forwarded_path",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://abc.com/en", true, "abc.com"),
+				},
+			},
+			want: []Finding{
+				{Kind: KindSelfRedirect, RuleIDs: []string{"rule-1", "rule-1"}, URLs: []string{"abc.com", "https://abc.com/en"}},
+			},
+		},
+		{
+			name: "loop",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "xyz.com/b", false, "abc.com/a"),
+					newRule("rule-2", "abc.com/a", false, "xyz.com/b"),
+				},
+			},
+			want: []Finding{
+				{Kind: KindLoop, RuleIDs: []string{"rule-1", "rule-2", "rule-1"}, URLs: []string{"abc.com/a", "http://xyz.com/b", "http://abc.com/a"}},
+			},
+		},
+		{
+			name: "chain",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://b.com/docs", true, "a.com/docs"),
+					newRule("rule-2", "https://c.com/help", true, "b.com/docs"),
+					newRule("rule-3", "https://d.com", false, "d.com/x"),
+				},
+			},
+			want: []Finding{
+				{
+					Kind:       KindChain,
+					RuleIDs:    []string{"rule-1", "rule-2"},
+					URLs:       []string{"a.com/docs", "https://b.com/docs", "https://c.com/help"},
+					Suggestion: "set the target of rule-1 to https://c.com/help",
+				},
+			},
+		},
+		{
+			name: "chain_forwarded_path",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://b.com/docs/guide", false, "a.com/docs"),
+					newRule("rule-2", "https://c.com/help", true, "b.com/docs"),
+				},
+			},
+			want: []Finding{
+				{
+					Kind:    KindChain,
+					RuleIDs: []string{"rule-1", "rule-2"},
+					URLs:    []string{"a.com/docs", "https://b.com/docs/guide", "https://c.com/help/guide"},
+				},
+			},
+		},
+		{
+			name: "chain_within_limit",
+			args: Args{
+				rules: []rule.Data{
+					newRule("rule-1", "https://b.com/docs", true, "a.com/docs"),
+					newRule("rule-2", "https://c.com/help", true, "b.com/docs"),
+				},
+				maxHops: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Redirects(rule.Rules{Data: tt.args.rules}, host.Hosts{Data: tt.args.hosts}, tt.args.maxHops)
+			td.Cmp(t, got, tt.want)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	td.Cmp(t, Format(nil), "No problems found.\n")
+
+	td.Cmp(t, Format([]Finding{
+		{Kind: KindLoop, RuleIDs: []string{"rule-1", "rule-2", "rule-1"}, URLs: []string{"a.com", "http://b.com", "http://a.com"}},
+		{Kind: KindChain, RuleIDs: []string{"rule-3", "rule-4"}, URLs: []string{"c.com", "http://d.com", "http://e.com"}, Suggestion: "set the target of rule-3 to http://e.com"},
+	}), heredoc.Doc(`
+		loop: rule-1 -> rule-2 -> rule-1: a.com -> http://b.com -> http://a.com
+		chain of 2 hops: rule-3 -> rule-4: c.com -> http://d.com -> http://e.com
+		    suggestion: set the target of rule-3 to http://e.com
+
+		2 problems found.
+	`))
+}
@@ -1,7 +1,10 @@
 package option
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
+	"time"
 )
 
 type Option interface {
@@ -12,14 +15,34 @@ type Doer interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+type Middleware func(next Doer) Doer
+
+type Limiter interface {
+	Wait(ctx context.Context) error
+	Update(limit, remaining int, reset time.Time)
+}
+
 type Options struct {
-	BaseURL      string
-	APIKey       string
-	APISecret    string
-	HTTPClient   Doer
-	SourceFilter string
-	TargetFilter string
-	Limit        int
-	Include      string
-	Pagination   Pagination
+	BaseURL        string
+	APIKey         string
+	APISecret      string
+	HTTPClient     Doer
+	SourceFilter   string
+	TargetFilter   string
+	Limit          int
+	Include        string
+	Pagination     Pagination
+	SkipValidation bool
+	Retry          Retry
+	Limiter        Limiter
+	Middlewares    []Middleware
+	Logger         *slog.Logger
+	Redactions     []string
+}
+
+// Validation turns client side validation of attributes on or off.
+type Validation bool
+
+func (v Validation) Apply(o *Options) {
+	o.SkipValidation = !bool(v)
 }
@@ -1,5 +1,13 @@
 package option
 
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+var ErrNoCursor = errors.New("no cursor")
+
 type Metadata struct {
 	HasMore bool `json:"has_more,omitempty"`
 }
@@ -13,3 +21,33 @@ type Pagination struct {
 	StartingAfter string
 	EndingBefore  string
 }
+
+func (p Pagination) Apply(o *Options) {
+	o.Pagination = p
+}
+
+func (l Links) NextCursor() (string, error) {
+	return cursor(l.Next, "starting_after")
+}
+
+func (l Links) PrevCursor() (string, error) {
+	return cursor(l.Prev, "ending_before")
+}
+
+func cursor(link, param string) (string, error) {
+	if link == "" {
+		return "", fmt.Errorf("%w: empty link", ErrNoCursor)
+	}
+
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("unable to parse link: %w", err)
+	}
+
+	c := u.Query().Get(param)
+	if c == "" {
+		return "", fmt.Errorf("%w: %v missing from link: %v", ErrNoCursor, param, link)
+	}
+
+	return c, nil
+}
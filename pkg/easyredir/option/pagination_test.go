@@ -0,0 +1,98 @@
+package option
+
+import (
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinksCursor(t *testing.T) {
+	type Want struct {
+		next    string
+		prev    string
+		nextErr string
+		prevErr string
+	}
+
+	tests := []struct {
+		name string
+		give Links
+		want Want
+	}{
+		{
+			name: "simple",
+			give: Links{
+				Next: "/v1/rules?starting_after=abc-def",
+				Prev: "/v1/rules?ending_before=abc-def",
+			},
+			want: Want{
+				next: "abc-def",
+				prev: "abc-def",
+			},
+		},
+		{
+			name: "additional_params",
+			give: Links{
+				Next: "/v1/rules?limit=10&starting_after=abc-def&sq=http%3A%2F%2Fexample.com%3Fa%3Db",
+				Prev: "https://api.easyredir.com/v1/rules?sq=x&ending_before=bcd-efg&limit=10",
+			},
+			want: Want{
+				next: "abc-def",
+				prev: "bcd-efg",
+			},
+		},
+		{
+			name: "empty",
+			give: Links{},
+			want: Want{
+				nextErr: "no cursor: empty link",
+				prevErr: "no cursor: empty link",
+			},
+		},
+		{
+			name: "missing_param",
+			give: Links{
+				Next: "/v1/rules?limit=10",
+				Prev: "/v1/rules?starting_after=abc-def",
+			},
+			want: Want{
+				nextErr: "no cursor: starting_after missing from link",
+				prevErr: "no cursor: ending_before missing from link",
+			},
+		},
+		{
+			name: "invalid",
+			give: Links{
+				Next: "/v1/rules?%zz",
+				Prev: "%zz",
+			},
+			want: Want{
+				nextErr: "no cursor",
+				prevErr: "unable to parse link",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := tt.give.NextCursor()
+			if tt.want.nextErr != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.nextErr)
+			} else {
+				assert.Nil(t, err)
+				td.Cmp(t, next, tt.want.next)
+			}
+
+			prev, err := tt.give.PrevCursor()
+			if tt.want.prevErr != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.prevErr)
+			} else {
+				assert.Nil(t, err)
+				td.Cmp(t, prev, tt.want.prev)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package option
+
+import (
+	"time"
+)
+
+type Retry struct {
+	MaxAttempts int
+	MinBackoff  time.Duration
+	MaxBackoff  time.Duration
+}
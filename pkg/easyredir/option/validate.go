@@ -0,0 +1,79 @@
+package option
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnsupportedOption = errors.New("unsupported option")
+
+// Field identifies an operation level option. Operations declare the fields
+// they support and any other option that has been set is rejected.
+type Field uint
+
+const (
+	FieldSourceFilter Field = 1 << iota
+	FieldTargetFilter
+	FieldLimit
+	FieldInclude
+	FieldPagination
+	FieldSkipValidation
+)
+
+func New(supported Field, opts ...Option) (*Options, error) {
+	o := &Options{}
+	for _, opt := range opts {
+		opt.Apply(o)
+	}
+
+	if err := o.Validate(supported); err != nil {
+		return o, err
+	}
+
+	return o, nil
+}
+
+func (o *Options) Validate(supported Field) error {
+	fields := []struct {
+		field Field
+		name  string
+		set   bool
+	}{
+		{FieldSourceFilter, "source filter", o.SourceFilter != ""},
+		{FieldTargetFilter, "target filter", o.TargetFilter != ""},
+		{FieldLimit, "limit", o.Limit != 0},
+		{FieldInclude, "include", o.Include != ""},
+		{FieldPagination, "pagination", o.Pagination != Pagination{}},
+		{FieldSkipValidation, "skip validation", o.SkipValidation},
+	}
+
+	var unsupported []string
+	for _, f := range fields {
+		if f.set && supported&f.field == 0 {
+			unsupported = append(unsupported, f.name)
+		}
+	}
+
+	if o.hasClientOptions() {
+		unsupported = append(unsupported, "client")
+	}
+
+	if len(unsupported) != 0 {
+		return fmt.Errorf("%w: %v", ErrUnsupportedOption, strings.Join(unsupported, ", "))
+	}
+
+	return nil
+}
+
+func (o *Options) hasClientOptions() bool {
+	return o.BaseURL != "" ||
+		o.APIKey != "" ||
+		o.APISecret != "" ||
+		o.HTTPClient != nil ||
+		o.Retry != Retry{} ||
+		o.Limiter != nil ||
+		len(o.Middlewares) != 0 ||
+		o.Logger != nil ||
+		len(o.Redactions) != 0
+}
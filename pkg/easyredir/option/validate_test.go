@@ -0,0 +1,83 @@
+package option
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	type Args struct {
+		options   Options
+		supported Field
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want string
+	}{
+		{
+			name: "empty",
+			args: Args{
+				options: Options{},
+			},
+		},
+		{
+			name: "supported",
+			args: Args{
+				options: Options{
+					SourceFilter: "source.example.com",
+					Limit:        10,
+				},
+				supported: FieldSourceFilter | FieldLimit,
+			},
+		},
+		{
+			name: "unsupported",
+			args: Args{
+				options: Options{
+					SourceFilter: "source.example.com",
+					Include:      "source_hosts",
+				},
+				supported: FieldLimit,
+			},
+			want: "unsupported option: source filter, include",
+		},
+		{
+			name: "pagination",
+			args: Args{
+				options: Options{
+					Pagination: Pagination{StartingAfter: "abc-def"},
+				},
+				supported: FieldInclude,
+			},
+			want: "unsupported option: pagination",
+		},
+		{
+			name: "client",
+			args: Args{
+				options: Options{
+					APIKey: "key",
+				},
+				supported: FieldSourceFilter | FieldTargetFilter | FieldLimit | FieldInclude | FieldPagination,
+			},
+			want: "unsupported option: client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.args.options.Validate(tt.args.supported)
+			if tt.want != "" {
+				assert.NotNil(t, err)
+				td.CmpTrue(t, errors.Is(err, ErrUnsupportedOption))
+				td.CmpContains(t, err, tt.want)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
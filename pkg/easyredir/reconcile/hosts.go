@@ -0,0 +1,194 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+)
+
+// Host is the desired settings of a host. Only the fields that are set are
+// managed, anything left out is not compared or changed. The API only reports
+// whether a host has a custom 404 body, so the body is set when the host has
+// none and is otherwise left as it is.
+type Host struct {
+	MatchOptions   host.MatchOptions   `json:"match_options,omitempty"`
+	NotFoundAction host.NotFoundAction `json:"not_found_action,omitempty"`
+	Security       host.Security       `json:"security,omitempty"`
+}
+
+// HostChange is an update to the settings of a host. The attributes only
+// hold the fields that differ.
+type HostChange struct {
+	ID         string
+	Name       string
+	Attributes host.Attributes
+	Diff       []Diff
+}
+
+// PlanHosts compares the host settings in the manifest with the account.
+// Hosts can not be created so every host in the manifest must exist. A plan
+// with host changes means the settings have drifted from the manifest.
+func PlanHosts(ctx context.Context, cl ClientAPI, m Manifest) (p Plan, err error) {
+	if len(m.Hosts) == 0 {
+		return p, nil
+	}
+
+	hs, err := host.ListHostsPaginatorWithContext(ctx, cl)
+	if err != nil {
+		return p, fmt.Errorf("unable to list hosts: %w", err)
+	}
+
+	ids := make(map[string]string)
+	for _, d := range hs.Data {
+		ids[strings.ToLower(d.Attributes.Name)] = d.ID
+	}
+
+	var existing []host.Data
+
+	for _, name := range m.hostNames() {
+		id, ok := ids[strings.ToLower(name)]
+		if !ok {
+			return p, fmt.Errorf("unable to find host: %v", name)
+		}
+
+		h, err := host.GetHostWithContext(ctx, cl, id)
+		if err != nil {
+			return p, fmt.Errorf("unable to get host: %v: %w", name, err)
+		}
+		existing = append(existing, h.Data)
+	}
+
+	return planHosts(m, existing), nil
+}
+
+func planHosts(m Manifest, existing []host.Data) (p Plan) {
+	byName := make(map[string]host.Data)
+	for _, d := range existing {
+		byName[strings.ToLower(d.Attributes.Name)] = d
+	}
+
+	for _, name := range m.hostNames() {
+		d, ok := byName[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+
+		attr, diff := diffHost(d.Attributes, m.Hosts[name].attributes())
+		if len(diff) == 0 {
+			continue
+		}
+
+		p.Hosts = append(p.Hosts, HostChange{
+			ID:         d.ID,
+			Name:       name,
+			Attributes: attr,
+			Diff:       diff,
+		})
+	}
+
+	return p
+}
+
+// diffHost returns the desired attributes that differ from the existing ones
+// along with the field level diff.
+func diffHost(have, want host.Attributes) (a host.Attributes, diff []Diff) {
+	d := func(field string) func(string, string) {
+		return func(old, new string) {
+			diff = append(diff, Diff{Field: field, Old: old, New: new})
+		}
+	}
+
+	hm, wm := have.MatchOptions, want.MatchOptions
+	a.MatchOptions.CaseInsensitive = diffField(hm.CaseInsensitive, wm.CaseInsensitive, d("match_options.case_insensitive"))
+	a.MatchOptions.SlashInsensitive = diffField(hm.SlashInsensitive, wm.SlashInsensitive, d("match_options.slash_insensitive"))
+
+	hn, wn := have.NotFoundAction, want.NotFoundAction
+	a.NotFoundAction.ForwardParams = diffField(hn.ForwardParams, wn.ForwardParams, d("not_found_action.forward_params"))
+	a.NotFoundAction.ForwardPath = diffField(hn.ForwardPath, wn.ForwardPath, d("not_found_action.forward_path"))
+	if hn.Custom404BodyPresent == nil || !*hn.Custom404BodyPresent {
+		a.NotFoundAction.Custom404Body = diffField(nil, wn.Custom404Body, d("not_found_action.custom_404_body"))
+	}
+	a.NotFoundAction.ResponseCode = diffField(hn.ResponseCode, wn.ResponseCode, d("not_found_action.response_code"))
+	a.NotFoundAction.ResponseURL = diffField(hn.ResponseURL, wn.ResponseURL, d("not_found_action.response_url"))
+
+	hs, ws := have.Security, want.Security
+	a.Security.HTTPSUpgrade = diffField(hs.HTTPSUpgrade, ws.HTTPSUpgrade, d("security.https_upgrade"))
+	a.Security.PreventForeignEmbedding = diffField(hs.PreventForeignEmbedding, ws.PreventForeignEmbedding, d("security.prevent_foreign_embedding"))
+	a.Security.HSTSIncludeSubDomains = diffField(hs.HSTSIncludeSubDomains, ws.HSTSIncludeSubDomains, d("security.hsts_include_sub_domains"))
+	a.Security.HSTSMaxAge = diffField(hs.HSTSMaxAge, ws.HSTSMaxAge, d("security.hsts_max_age"))
+	a.Security.HSTSPreload = diffField(hs.HSTSPreload, ws.HSTSPreload, d("security.hsts_preload"))
+
+	return a, diff
+}
+
+// diffField returns the desired value when it is managed and differs from the
+// existing value.
+func diffField[T comparable](have, want *T, add func(old, new string)) *T {
+	if want == nil {
+		return nil
+	}
+
+	if have != nil && *have == *want {
+		return nil
+	}
+
+	add(fmtValue(have), fmtValue(want))
+
+	return want
+}
+
+func fmtValue[T comparable](v *T) string {
+	if v == nil {
+		return "(unset)"
+	}
+
+	if s, ok := any(*v).(string); ok {
+		return strconv.Quote(s)
+	}
+
+	return fmt.Sprint(*v)
+}
+
+func (h Host) attributes() host.Attributes {
+	return host.Attributes{
+		MatchOptions:   h.MatchOptions,
+		NotFoundAction: h.NotFoundAction,
+		Security:       h.Security,
+	}
+}
+
+func (m Manifest) hostNames() []string {
+	names := make([]string, 0, len(m.Hosts))
+	for name := range m.Hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Apply updates the host with the changed fields. The manifest is validated
+// when read, a patch with only the changed fields may not pass on its own.
+func (c HostChange) Apply(ctx context.Context, cl ClientAPI) error {
+	if _, err := host.UpdateHostWithContext(ctx, cl, c.ID, c.Attributes, option.Validation(false)); err != nil {
+		return fmt.Errorf("unable to update host: %v: %w", c.Name, err)
+	}
+
+	return nil
+}
+
+func (c HostChange) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%v %v host %v (%v)\n", symbol(ActionUpdate), ActionUpdate, c.Name, c.ID)
+	for _, d := range c.Diff {
+		fmt.Fprintf(&b, "    %v\n", d)
+	}
+
+	return b.String()
+}
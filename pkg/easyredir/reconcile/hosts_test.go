@@ -0,0 +1,248 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/stretchr/testify/assert"
+)
+
+func existingHost() host.Data {
+	return host.Data{
+		ID:   "host-1",
+		Type: "host",
+		Attributes: host.Attributes{
+			Name: "abc.com",
+			MatchOptions: host.MatchOptions{
+				CaseInsensitive:  ptr.Bool(true),
+				SlashInsensitive: ptr.Bool(true),
+			},
+			NotFoundAction: host.NotFoundAction{
+				ForwardParams: ptr.Bool(true),
+				ForwardPath:   ptr.Bool(true),
+				ResponseCode:  ref(host.ResponseCodeFound),
+				ResponseURL:   ptr.String("https://www.example.com"),
+			},
+			Security: host.Security{
+				HTTPSUpgrade:            ptr.Bool(true),
+				PreventForeignEmbedding: ptr.Bool(false),
+				HSTSIncludeSubDomains:   ptr.Bool(true),
+				HSTSMaxAge:              ptr.Int(31536000),
+				HSTSPreload:             ptr.Bool(false),
+			},
+		},
+	}
+}
+
+func TestPlanHostsDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		give    Manifest
+		present bool
+		want    Plan
+	}{
+		{
+			name: "no_drift",
+			give: Manifest{
+				Hosts: map[string]Host{
+					"ABC.com": {
+						MatchOptions: host.MatchOptions{CaseInsensitive: ptr.Bool(true)},
+						Security:     host.Security{HSTSMaxAge: ptr.Int(31536000)},
+					},
+				},
+			},
+		},
+		{
+			name: "custom_404_body_present",
+			give: Manifest{
+				Hosts: map[string]Host{
+					"abc.com": {
+						NotFoundAction: host.NotFoundAction{Custom404Body: ptr.String("Not here")},
+					},
+				},
+			},
+			present: true,
+		},
+		{
+			name: "unmanaged_fields",
+			give: Manifest{
+				Hosts: map[string]Host{
+					"abc.com": {},
+				},
+			},
+		},
+		{
+			name: "unknown_host",
+			give: Manifest{
+				Hosts: map[string]Host{
+					"xyz.com": {
+						MatchOptions: host.MatchOptions{CaseInsensitive: ptr.Bool(false)},
+					},
+				},
+			},
+		},
+		{
+			name: "drift",
+			give: Manifest{
+				Hosts: map[string]Host{
+					"abc.com": {
+						MatchOptions: host.MatchOptions{
+							CaseInsensitive:  ptr.Bool(false),
+							SlashInsensitive: ptr.Bool(true),
+						},
+						NotFoundAction: host.NotFoundAction{
+							Custom404Body: ptr.String("Not here"),
+							ResponseCode:  ref(host.ResponseCodeMovedPermanently),
+						},
+						Security: host.Security{
+							HSTSPreload: ptr.Bool(true),
+						},
+					},
+				},
+			},
+			want: Plan{
+				Hosts: []HostChange{
+					{
+						ID:   "host-1",
+						Name: "abc.com",
+						Attributes: host.Attributes{
+							MatchOptions: host.MatchOptions{
+								CaseInsensitive: ptr.Bool(false),
+							},
+							NotFoundAction: host.NotFoundAction{
+								Custom404Body: ptr.String("Not here"),
+								ResponseCode:  ref(host.ResponseCodeMovedPermanently),
+							},
+							Security: host.Security{
+								HSTSPreload: ptr.Bool(true),
+							},
+						},
+						Diff: []Diff{
+							{Field: "match_options.case_insensitive", Old: "true", New: "false"},
+							{Field: "not_found_action.custom_404_body", Old: "(unset)", New: `"Not here"`},
+							{Field: "not_found_action.response_code", Old: "302", New: "301"},
+							{Field: "security.hsts_preload", Old: "false", New: "true"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := existingHost()
+			d.Attributes.NotFoundAction.Custom404BodyPresent = ptr.Bool(tt.present)
+
+			got := planHosts(tt.give, []host.Data{d})
+			td.Cmp(t, got, tt.want)
+			td.Cmp(t, got.HasChanges(), len(tt.want.Hosts) > 0)
+		})
+	}
+}
+
+func TestPlanHosts(t *testing.T) {
+	type Want struct {
+		plan     string
+		requests []string
+		err      string
+	}
+
+	tests := []struct {
+		name string
+		give Manifest
+		want Want
+	}{
+		{
+			name: "drift",
+			give: Manifest{
+				Hosts: map[string]Host{
+					"abc.com": {
+						Security: host.Security{HTTPSUpgrade: ptr.Bool(false)},
+					},
+				},
+			},
+			want: Want{
+				plan: heredoc.Doc(`
+					~ update host abc.com (host-1)
+					    security.https_upgrade: true => false
+
+					Plan: 0 to create, 1 to update, 0 to delete.
+				`),
+				requests: []string{
+					"GET /hosts",
+					"GET /hosts/host-1",
+					`PATCH /hosts/host-1 {"match_options":{},"not_found_action":{},"security":{"https_upgrade":false},"required_dns_entries":{"recommended":{}}}`,
+				},
+			},
+		},
+		{
+			name: "missing",
+			give: Manifest{
+				Hosts: map[string]Host{
+					"xyz.com": {},
+				},
+			},
+			want: Want{
+				requests: []string{
+					"GET /hosts",
+				},
+				err: "unable to find host: xyz.com",
+			},
+		},
+		{
+			name: "none",
+			give: Manifest{},
+			want: Want{
+				plan: "No changes.\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+				body, _ := io.ReadAll(req.Body)
+				requests = append(requests, strings.TrimSpace(fmt.Sprintf("%v %v %s", req.Method, req.URL.Path, body)))
+
+				switch req.URL.Path {
+				case "/hosts":
+					w.Write([]byte(`{"data": [{"id": "host-1", "type": "host", "attributes": {"name": "abc.com"}}]}`))
+				default:
+					w.Write([]byte(`{"data": {"id": "host-1", "type": "host", "attributes": {"name": "abc.com", "security": {"https_upgrade": true}}}}`))
+				}
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			p, err := BuildPlan(context.Background(), cl, tt.give, false)
+			if tt.want.err != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.err)
+				td.Cmp(t, requests, tt.want.requests)
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, p.String(), tt.want.plan)
+
+			_, err = Apply(context.Background(), cl, p)
+			assert.Nil(t, err)
+			td.Cmp(t, requests, tt.want.requests)
+		})
+	}
+}
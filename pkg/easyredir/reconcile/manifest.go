@@ -0,0 +1,142 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/sourceurl"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+// Manifest is the desired state of an account. It is read from YAML or JSON.
+// Host settings are keyed by host name.
+type Manifest struct {
+	Rules []Rule          `json:"rules,omitempty"`
+	Hosts map[string]Host `json:"hosts,omitempty"`
+}
+
+// Rule is a desired rule. Omitted fields take the API defaults.
+type Rule struct {
+	SourceURLs    []string          `json:"source_urls"`
+	TargetURL     string            `json:"target_url"`
+	ResponseType  rule.ResponseType `json:"response_type,omitempty"`
+	ForwardParams bool              `json:"forward_params,omitempty"`
+	ForwardPath   bool              `json:"forward_path,omitempty"`
+}
+
+func LoadManifest(path string) (m Manifest, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return m, fmt.Errorf("unable to open manifest: %w", err)
+	}
+	defer f.Close()
+
+	return ReadManifest(f)
+}
+
+// ReadManifest decodes and validates a manifest. Unknown fields are rejected
+// so that typos do not silently fall back to defaults.
+func ReadManifest(r io.Reader) (m Manifest, err error) {
+	if err := yaml.NewDecoder(r, yaml.DisallowUnknownField()).Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return m, fmt.Errorf("unable to decode manifest: %w", err)
+	}
+
+	if err := m.Validate(); err != nil {
+		return m, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	return m, nil
+}
+
+// Validate checks each rule and host and that no source URL is claimed twice,
+// using the match options of the hosts in the manifest.
+func (m Manifest) Validate() error {
+	var errs []error
+
+	mo := m.hostOptions()
+	seen := make(map[string]int)
+
+	for i, r := range m.Rules {
+		if err := r.attributes().Validate(true); err != nil {
+			errs = append(errs, fmt.Errorf("rules[%d]: %w", i, err))
+		}
+
+		for _, s := range r.SourceURLs {
+			keys := mo.keys(s)
+
+			dup := -1
+			for _, k := range keys {
+				if j, ok := seen[k]; ok {
+					dup = j
+					break
+				}
+			}
+			if dup != -1 {
+				errs = append(errs, fmt.Errorf("rules[%d]: duplicate source URL: %v also in rules[%d]", i, s, dup))
+				continue
+			}
+
+			for _, k := range keys {
+				seen[k] = i
+			}
+		}
+	}
+
+	for _, name := range m.hostNames() {
+		if err := m.Hosts[name].attributes().Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("hosts[%v]: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r Rule) responseType() rule.ResponseType {
+	if r.ResponseType == "" {
+		return rule.ResponseMovedPermanently
+	}
+
+	return r.ResponseType
+}
+
+func (r Rule) attributes() rule.Attributes {
+	rt := r.responseType()
+
+	return rule.Attributes{
+		ForwardParams: &r.ForwardParams,
+		ForwardPath:   &r.ForwardPath,
+		ResponseType:  &rt,
+		SourceURLs:    r.SourceURLs,
+		TargetURL:     &r.TargetURL,
+	}
+}
+
+// matchOptions holds host match options by lower case host name.
+type matchOptions map[string]host.MatchOptions
+
+// key normalises a source URL the way its host matches requests, so that
+// sources the host treats as the same are matched.
+func (mo matchOptions) key(s string) string {
+	return sourceurl.Normalize(s, mo[sourceurl.Hostname(s)])
+}
+
+// keys returns the source URL under each scheme it matches, as a source
+// without a scheme overlaps sources for both http and https.
+func (mo matchOptions) keys(s string) []string {
+	return sourceurl.Keys(s, mo[sourceurl.Hostname(s)])
+}
+
+// hostOptions returns the match options set for the hosts in the manifest.
+func (m Manifest) hostOptions() matchOptions {
+	mo := make(matchOptions)
+	for name, h := range m.Hosts {
+		mo[strings.ToLower(name)] = h.MatchOptions
+	}
+
+	return mo
+}
@@ -0,0 +1,206 @@
+package reconcile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadManifest(t *testing.T) {
+	type Want struct {
+		manifest Manifest
+		err      string
+	}
+
+	tests := []struct {
+		name string
+		give string
+		want Want
+	}{
+		{
+			name: "yaml",
+			give: heredoc.Doc(`
+				rules:
+				  - source_urls:
+				      - abc.com
+				      - abc.com/123
+				    target_url: https://otherdomain.com
+				    response_type: found
+				    forward_path: true
+			`),
+			want: Want{
+				manifest: Manifest{
+					Rules: []Rule{
+						{
+							SourceURLs:   []string{"abc.com", "abc.com/123"},
+							TargetURL:    "https://otherdomain.com",
+							ResponseType: rule.ResponseFound,
+							ForwardPath:  true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "json",
+			give: `{"rules": [{"source_urls": ["abc.com"], "target_url": "otherdomain.com"}]}`,
+			want: Want{
+				manifest: Manifest{
+					Rules: []Rule{
+						{
+							SourceURLs: []string{"abc.com"},
+							TargetURL:  "otherdomain.com",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "hosts",
+			give: heredoc.Doc(`
+				hosts:
+				  abc.com:
+				    match_options:
+				      case_insensitive: true
+				    security:
+				      hsts_include_sub_domains: true
+				      hsts_max_age: 31536000
+				      hsts_preload: true
+			`),
+			want: Want{
+				manifest: Manifest{
+					Hosts: map[string]Host{
+						"abc.com": {
+							MatchOptions: host.MatchOptions{
+								CaseInsensitive: ptr.Bool(true),
+							},
+							Security: host.Security{
+								HSTSIncludeSubDomains: ptr.Bool(true),
+								HSTSMaxAge:            ptr.Int(31536000),
+								HSTSPreload:           ptr.Bool(true),
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid_host",
+			give: heredoc.Doc(`
+				hosts:
+				  abc.com:
+				    security:
+				      hsts_preload: true
+			`),
+			want: Want{
+				err: "hosts[abc.com]: invalid_request_error: Invalid host",
+			},
+		},
+		{
+			name: "empty",
+			give: "",
+		},
+		{
+			name: "unknown_field",
+			give: `{"rules": [{"source_url": ["abc.com"], "target_url": "otherdomain.com"}]}`,
+			want: Want{
+				err: "unable to decode manifest",
+			},
+		},
+		{
+			name: "invalid_rule",
+			give: `{"rules": [{"source_urls": ["abc.com"]}]}`,
+			want: Want{
+				err: "rules[0]: invalid_request_error: Invalid rule",
+			},
+		},
+		{
+			name: "duplicate_source",
+			give: heredoc.Doc(`
+				rules:
+				  - source_urls: [abc.com/123]
+				    target_url: otherdomain.com
+				  - source_urls: [ABC.com/123]
+				    target_url: thirddomain.com
+			`),
+			want: Want{
+				err: "rules[1]: duplicate source URL: ABC.com/123 also in rules[0]",
+			},
+		},
+		{
+			name: "duplicate_source_scheme",
+			give: heredoc.Doc(`
+				rules:
+				  - source_urls: [abc.com/123]
+				    target_url: otherdomain.com
+				  - source_urls: [https://abc.com/123]
+				    target_url: thirddomain.com
+			`),
+			want: Want{
+				err: "rules[1]: duplicate source URL: https://abc.com/123 also in rules[0]",
+			},
+		},
+		{
+			name: "duplicate_source_insensitive",
+			give: heredoc.Doc(`
+				rules:
+				  - source_urls: [abc.com/about]
+				    target_url: otherdomain.com
+				  - source_urls: [abc.com/About/]
+				    target_url: thirddomain.com
+				hosts:
+				  abc.com:
+				    match_options:
+				      case_insensitive: true
+				      slash_insensitive: true
+			`),
+			want: Want{
+				err: "rules[1]: duplicate source URL: abc.com/About/ also in rules[0]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadManifest(strings.NewReader(tt.give))
+			if tt.want.err != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.err)
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, got, tt.want.manifest)
+		})
+	}
+}
+
+func TestMatchOptionsKey(t *testing.T) {
+	mo := matchOptions{
+		"abc.com": {CaseInsensitive: ptr.Bool(true), SlashInsensitive: ptr.Bool(true)},
+	}
+
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{name: "host", give: "abc.com", want: "abc.com/"},
+		{name: "root", give: "abc.com/", want: "abc.com/"},
+		{name: "insensitive", give: "ABC.com/Path/", want: "abc.com/path"},
+		{name: "sensitive", give: "xyz.com/Path/", want: "xyz.com/Path/"},
+		{name: "scheme", give: "HTTPS://abc.com/path", want: "https://abc.com/path"},
+		{name: "query", give: " xyz.com/path?b=1&a=2 ", want: "xyz.com/path?a=2&b=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, mo.key(tt.give), tt.want)
+		})
+	}
+}
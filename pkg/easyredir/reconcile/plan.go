@@ -0,0 +1,140 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type ClientAPI interface {
+	SendRequest(path, method string, body io.Reader) (io.ReadCloser, error)
+}
+
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Diff is a change to a single field. Values are formatted for display.
+type Diff struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Plan is the set of changes needed to reach the manifest. Changes are kept
+// in the order they are applied.
+type Plan struct {
+	Rules []RuleChange
+	Hosts []HostChange
+}
+
+// Result counts the changes that were applied.
+type Result struct {
+	Created int
+	Updated int
+	Deleted int
+}
+
+const (
+	NoChanges = "No changes."
+)
+
+func (p Plan) HasChanges() bool {
+	return len(p.Rules) > 0 || len(p.Hosts) > 0
+}
+
+// BuildPlan plans both rules and hosts. Rules are only listed when the
+// manifest has rules or pruning was requested.
+func BuildPlan(ctx context.Context, cl ClientAPI, m Manifest, prune bool) (p Plan, err error) {
+	if len(m.Rules) > 0 || prune {
+		if p, err = PlanRules(ctx, cl, m, prune); err != nil {
+			return p, err
+		}
+	}
+
+	hp, err := PlanHosts(ctx, cl, m)
+	if err != nil {
+		return p, err
+	}
+	p.Hosts = hp.Hosts
+
+	return p, nil
+}
+
+// Apply makes the changes in the plan. It stops at the first failure and
+// returns what was applied until then.
+func Apply(ctx context.Context, cl ClientAPI, p Plan) (res Result, err error) {
+	for _, c := range p.Rules {
+		if err := c.Apply(ctx, cl); err != nil {
+			return res, err
+		}
+		res.count(c.Action)
+	}
+
+	for _, c := range p.Hosts {
+		if err := c.Apply(ctx, cl); err != nil {
+			return res, err
+		}
+		res.count(ActionUpdate)
+	}
+
+	return res, nil
+}
+
+func (r *Result) count(a Action) {
+	switch a {
+	case ActionCreate:
+		r.Created++
+	case ActionUpdate:
+		r.Updated++
+	case ActionDelete:
+		r.Deleted++
+	}
+}
+
+func (p Plan) String() string {
+	if !p.HasChanges() {
+		return NoChanges + "\n"
+	}
+
+	var b strings.Builder
+	var res Result
+
+	for _, c := range p.Rules {
+		fmt.Fprint(&b, c)
+		res.count(c.Action)
+	}
+
+	for _, c := range p.Hosts {
+		fmt.Fprint(&b, c)
+		res.count(ActionUpdate)
+	}
+
+	fmt.Fprintf(&b, "\nPlan: %v to create, %v to update, %v to delete.\n", res.Created, res.Updated, res.Deleted)
+
+	return b.String()
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("Applied: %v created, %v updated, %v deleted.", r.Created, r.Updated, r.Deleted)
+}
+
+func (d Diff) String() string {
+	return fmt.Sprintf("%v: %v => %v", d.Field, d.Old, d.New)
+}
+
+func symbol(a Action) string {
+	switch a {
+	case ActionCreate:
+		return "+"
+	case ActionDelete:
+		return "-"
+	default:
+		return "~"
+	}
+}
@@ -0,0 +1,350 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/sourceurl"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+// RuleChange is a single rule to create, update or delete. For updates the
+// attributes only hold the fields that differ.
+type RuleChange struct {
+	Action     Action
+	ID         string
+	SourceURLs []string
+	Attributes rule.Attributes
+	Diff       []Diff
+}
+
+// PlanRules compares the manifest with the rules in the account. Rules are
+// matched on their source URLs, using the match options of their hosts.
+// Existing rules that are not in the manifest are only deleted when pruning,
+// except that any source URLs claimed by the manifest are always taken from
+// them. A matched rule whose sources all move to other rules is replaced.
+func PlanRules(ctx context.Context, cl ClientAPI, m Manifest, prune bool) (p Plan, err error) {
+	r, err := rule.ListRulesPaginatorWithContext(ctx, cl)
+	if err != nil {
+		return p, fmt.Errorf("unable to list rules: %w", err)
+	}
+
+	mo, err := hostMatchOptions(ctx, cl, r.Data)
+	if err != nil {
+		return p, err
+	}
+
+	return planRules(m, r.Data, mo, prune), nil
+}
+
+// hostMatchOptions gets the match options of the hosts used by the existing
+// rules. Only these matter, a source on any other host cannot match. Listing
+// hosts does not return their settings so each host is fetched.
+func hostMatchOptions(ctx context.Context, cl ClientAPI, existing []rule.Data) (matchOptions, error) {
+	names := make(map[string]bool)
+	for _, d := range existing {
+		for _, s := range d.Attributes.SourceURLs {
+			names[sourceurl.Hostname(s)] = true
+		}
+	}
+
+	mo := make(matchOptions)
+	if len(names) == 0 {
+		return mo, nil
+	}
+
+	hs, err := host.ListHostsPaginatorWithContext(ctx, cl)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list hosts: %w", err)
+	}
+
+	for _, d := range hs.Data {
+		name := strings.ToLower(d.Attributes.Name)
+		if !names[name] {
+			continue
+		}
+
+		h, err := host.GetHostWithContext(ctx, cl, d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get host: %v: %w", d.Attributes.Name, err)
+		}
+		mo[name] = h.Data.Attributes.MatchOptions
+	}
+
+	return mo, nil
+}
+
+func planRules(m Manifest, existing []rule.Data, mo matchOptions, prune bool) Plan {
+	owner := make(map[string]int)
+	for i, d := range existing {
+		for _, s := range d.Attributes.SourceURLs {
+			for _, k := range mo.keys(s) {
+				if _, ok := owner[k]; !ok {
+					owner[k] = i
+				}
+			}
+		}
+	}
+
+	claimant := make(map[string]int)
+	claimed := make(map[int]bool)
+	match := make([]int, len(m.Rules))
+
+	for i, want := range m.Rules {
+		overlap := make(map[int]int)
+		for _, s := range want.SourceURLs {
+			owners := make(map[int]bool)
+			for _, k := range mo.keys(s) {
+				claimant[k] = i
+				if j, ok := owner[k]; ok && !claimed[j] {
+					owners[j] = true
+				}
+			}
+			for j := range owners {
+				overlap[j]++
+			}
+		}
+
+		match[i] = -1
+		for j, n := range overlap {
+			if match[i] == -1 || n > overlap[match[i]] || (n == overlap[match[i]] && j < match[i]) {
+				match[i] = j
+			}
+		}
+		if match[i] != -1 {
+			claimed[match[i]] = true
+		}
+	}
+
+	var deletes, trims, updates, creates []RuleChange
+
+	for j, d := range existing {
+		if claimed[j] {
+			continue
+		}
+
+		var keep []string
+		for _, s := range d.Attributes.SourceURLs {
+			if !slices.ContainsFunc(mo.keys(s), func(k string) bool {
+				_, ok := claimant[k]
+				return ok
+			}) {
+				keep = append(keep, s)
+			}
+		}
+
+		switch {
+		case prune || len(keep) == 0:
+			deletes = append(deletes, RuleChange{
+				Action:     ActionDelete,
+				ID:         d.ID,
+				SourceURLs: d.Attributes.SourceURLs,
+			})
+		case len(keep) < len(d.Attributes.SourceURLs):
+			trims = append(trims, RuleChange{
+				Action:     ActionUpdate,
+				ID:         d.ID,
+				SourceURLs: d.Attributes.SourceURLs,
+				Attributes: rule.Attributes{SourceURLs: keep},
+				Diff: []Diff{
+					{Field: "source_urls", Old: fmt.Sprint(d.Attributes.SourceURLs), New: fmt.Sprint(keep)},
+				},
+			})
+		}
+	}
+
+	for i, want := range m.Rules {
+		if match[i] == -1 {
+			creates = append(creates, RuleChange{
+				Action:     ActionCreate,
+				SourceURLs: want.SourceURLs,
+				Attributes: want.attributes(),
+				Diff:       diffRule(rule.Attributes{}, want.attributes(), mo),
+			})
+			continue
+		}
+
+		d := existing[match[i]]
+		diff := diffRule(d.Attributes, want.attributes(), mo)
+		if len(diff) == 0 {
+			continue
+		}
+
+		// Sources moving to another matched rule are released before any
+		// update runs, otherwise swapping sources between rules fails. A
+		// rule left without sources is replaced instead.
+		var keep []string
+		for _, s := range d.Attributes.SourceURLs {
+			if !slices.ContainsFunc(mo.keys(s), func(k string) bool {
+				j, ok := claimant[k]
+				return ok && j != i
+			}) {
+				keep = append(keep, s)
+			}
+		}
+
+		switch {
+		case len(keep) == 0:
+			deletes = append(deletes, RuleChange{
+				Action:     ActionDelete,
+				ID:         d.ID,
+				SourceURLs: d.Attributes.SourceURLs,
+			})
+			creates = append(creates, RuleChange{
+				Action:     ActionCreate,
+				SourceURLs: want.SourceURLs,
+				Attributes: want.attributes(),
+				Diff:       diffRule(rule.Attributes{}, want.attributes(), mo),
+			})
+			continue
+		case len(keep) < len(d.Attributes.SourceURLs):
+			trims = append(trims, RuleChange{
+				Action:     ActionUpdate,
+				ID:         d.ID,
+				SourceURLs: d.Attributes.SourceURLs,
+				Attributes: rule.Attributes{SourceURLs: keep},
+				Diff: []Diff{
+					{Field: "source_urls", Old: fmt.Sprint(d.Attributes.SourceURLs), New: fmt.Sprint(keep)},
+				},
+			})
+		}
+
+		updates = append(updates, RuleChange{
+			Action:     ActionUpdate,
+			ID:         d.ID,
+			SourceURLs: d.Attributes.SourceURLs,
+			Attributes: patchRule(d.Attributes, want.attributes(), mo),
+			Diff:       diff,
+		})
+	}
+
+	// Sources must be released by deletes and trims before updates and
+	// creates claim them.
+	var p Plan
+	p.Rules = append(p.Rules, deletes...)
+	p.Rules = append(p.Rules, trims...)
+	p.Rules = append(p.Rules, updates...)
+	p.Rules = append(p.Rules, creates...)
+
+	return p
+}
+
+func diffRule(have, want rule.Attributes, mo matchOptions) (diff []Diff) {
+	add := func(field, old, new string) {
+		if old != new {
+			diff = append(diff, Diff{Field: field, Old: old, New: new})
+		}
+	}
+
+	if !sameSources(have.SourceURLs, want.SourceURLs, mo) {
+		diff = append(diff, Diff{Field: "source_urls", Old: fmt.Sprint(have.SourceURLs), New: fmt.Sprint(want.SourceURLs)})
+	}
+	add("target_url", fmtString(have.TargetURL), fmtString(want.TargetURL))
+	add("response_type", fmtResponseType(have.ResponseType), fmtResponseType(want.ResponseType))
+	add("forward_params", fmtBool(have.ForwardParams), fmtBool(want.ForwardParams))
+	add("forward_path", fmtBool(have.ForwardPath), fmtBool(want.ForwardPath))
+
+	return diff
+}
+
+// patchRule returns the desired attributes that differ from the existing
+// ones.
+func patchRule(have, want rule.Attributes, mo matchOptions) (a rule.Attributes) {
+	if !sameSources(have.SourceURLs, want.SourceURLs, mo) {
+		a.SourceURLs = want.SourceURLs
+	}
+	if fmtString(have.TargetURL) != fmtString(want.TargetURL) {
+		a.TargetURL = want.TargetURL
+	}
+	if fmtResponseType(have.ResponseType) != fmtResponseType(want.ResponseType) {
+		a.ResponseType = want.ResponseType
+	}
+	if fmtBool(have.ForwardParams) != fmtBool(want.ForwardParams) {
+		a.ForwardParams = want.ForwardParams
+	}
+	if fmtBool(have.ForwardPath) != fmtBool(want.ForwardPath) {
+		a.ForwardPath = want.ForwardPath
+	}
+
+	return a
+}
+
+func sameSources(a, b []string, mo matchOptions) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	ka := make([]string, len(a))
+	kb := make([]string, len(b))
+	for i := range a {
+		ka[i] = mo.key(a[i])
+		kb[i] = mo.key(b[i])
+	}
+	slices.Sort(ka)
+	slices.Sort(kb)
+
+	return slices.Equal(ka, kb)
+}
+
+// Apply makes the single change.
+func (c RuleChange) Apply(ctx context.Context, cl ClientAPI) error {
+	var err error
+
+	switch c.Action {
+	case ActionCreate:
+		_, err = rule.CreateRuleWithContext(ctx, cl, c.Attributes)
+	case ActionUpdate:
+		_, err = rule.UpdateRuleWithContext(ctx, cl, c.ID, c.Attributes)
+	case ActionDelete:
+		_, err = rule.RemoveRuleWithContext(ctx, cl, c.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to %v rule: %v: %w", c.Action, c.label(), err)
+	}
+
+	return nil
+}
+
+func (c RuleChange) label() string {
+	s := strings.Join(c.SourceURLs, ", ")
+	if c.ID == "" {
+		return s
+	}
+
+	return fmt.Sprintf("%v (%v)", c.ID, s)
+}
+
+func (c RuleChange) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%v %v rule %v\n", symbol(c.Action), c.Action, c.label())
+	for _, d := range c.Diff {
+		fmt.Fprintf(&b, "    %v\n", d)
+	}
+
+	return b.String()
+}
+
+func fmtString(s *string) string {
+	if s == nil {
+		return `""`
+	}
+
+	return strconv.Quote(*s)
+}
+
+func fmtBool(b *bool) string {
+	return strconv.FormatBool(b != nil && *b)
+}
+
+func fmtResponseType(rt *rule.ResponseType) string {
+	if rt == nil || *rt == "" {
+		return string(rule.ResponseMovedPermanently)
+	}
+
+	return string(*rt)
+}
@@ -0,0 +1,577 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+type WithBaseURL string
+
+func (u WithBaseURL) Apply(o *option.Options) {
+	o.BaseURL = string(u)
+}
+
+func existingRule(id, target string, sources ...string) rule.Data {
+	return rule.Data{
+		ID:   id,
+		Type: "rule",
+		Attributes: rule.Attributes{
+			ForwardParams: ptr.Bool(false),
+			ForwardPath:   ptr.Bool(false),
+			ResponseType:  ref(rule.ResponseMovedPermanently),
+			SourceURLs:    sources,
+			TargetURL:     ptr.String(target),
+		},
+	}
+}
+
+func TestPlanRules(t *testing.T) {
+	type Args struct {
+		manifest Manifest
+		existing []rule.Data
+		options  matchOptions
+		prune    bool
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Plan
+	}{
+		{
+			name: "no_changes",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com/", "ABC.com/123"}, TargetURL: "https://otherdomain.com"},
+					},
+				},
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com/123", "abc.com"),
+				},
+			},
+		},
+		{
+			name: "case_insensitive",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com/About"}, TargetURL: "https://otherdomain.com"},
+					},
+				},
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com/about"),
+				},
+				options: matchOptions{
+					"abc.com": {CaseInsensitive: ptr.Bool(true)},
+				},
+			},
+		},
+		{
+			name: "case_sensitive",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com/About"}, TargetURL: "https://otherdomain.com"},
+					},
+				},
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com/about"),
+				},
+			},
+			want: Plan{
+				Rules: []RuleChange{
+					{
+						Action:     ActionCreate,
+						SourceURLs: []string{"abc.com/About"},
+						Attributes: rule.Attributes{
+							ForwardParams: ptr.Bool(false),
+							ForwardPath:   ptr.Bool(false),
+							ResponseType:  ref(rule.ResponseMovedPermanently),
+							SourceURLs:    []string{"abc.com/About"},
+							TargetURL:     ptr.String("https://otherdomain.com"),
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[]", New: "[abc.com/About]"},
+							{Field: "target_url", Old: `""`, New: `"https://otherdomain.com"`},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "scheme",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com/a"}, TargetURL: "https://otherdomain.com"},
+					},
+				},
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "http://abc.com/a"),
+				},
+			},
+			want: Plan{
+				Rules: []RuleChange{
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-1",
+						SourceURLs: []string{"http://abc.com/a"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/a"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[http://abc.com/a]", New: "[abc.com/a]"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "create",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com"}, TargetURL: "https://otherdomain.com", ResponseType: rule.ResponseFound},
+					},
+				},
+			},
+			want: Plan{
+				Rules: []RuleChange{
+					{
+						Action:     ActionCreate,
+						SourceURLs: []string{"abc.com"},
+						Attributes: rule.Attributes{
+							ForwardParams: ptr.Bool(false),
+							ForwardPath:   ptr.Bool(false),
+							ResponseType:  ref(rule.ResponseFound),
+							SourceURLs:    []string{"abc.com"},
+							TargetURL:     ptr.String("https://otherdomain.com"),
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[]", New: "[abc.com]"},
+							{Field: "target_url", Old: `""`, New: `"https://otherdomain.com"`},
+							{Field: "response_type", Old: "moved_permanently", New: "found"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "update",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com", "abc.com/new"}, TargetURL: "https://newdomain.com", ForwardPath: true},
+					},
+				},
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com"),
+				},
+			},
+			want: Plan{
+				Rules: []RuleChange{
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-1",
+						SourceURLs: []string{"abc.com"},
+						Attributes: rule.Attributes{
+							ForwardPath: ptr.Bool(true),
+							SourceURLs:  []string{"abc.com", "abc.com/new"},
+							TargetURL:   ptr.String("https://newdomain.com"),
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com]", New: "[abc.com abc.com/new]"},
+							{Field: "target_url", Old: `"https://otherdomain.com"`, New: `"https://newdomain.com"`},
+							{Field: "forward_path", Old: "false", New: "true"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unmanaged_kept",
+			args: Args{
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com"),
+				},
+			},
+		},
+		{
+			name: "unmanaged_pruned",
+			args: Args{
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com"),
+				},
+				prune: true,
+			},
+			want: Plan{
+				Rules: []RuleChange{
+					{Action: ActionDelete, ID: "rule-1", SourceURLs: []string{"abc.com"}},
+				},
+			},
+		},
+		{
+			name: "claimed_sources",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com/1", "abc.com/2", "abc.com/3"}, TargetURL: "https://otherdomain.com"},
+					},
+				},
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com/1"),
+					existingRule("rule-2", "https://otherdomain.com", "abc.com/2", "abc.com/3"),
+					existingRule("rule-3", "https://otherdomain.com", "abc.com/1", "abc.com/4"),
+				},
+			},
+			want: Plan{
+				Rules: []RuleChange{
+					{Action: ActionDelete, ID: "rule-1", SourceURLs: []string{"abc.com/1"}},
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-3",
+						SourceURLs: []string{"abc.com/1", "abc.com/4"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/4"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com/1 abc.com/4]", New: "[abc.com/4]"},
+						},
+					},
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-2",
+						SourceURLs: []string{"abc.com/2", "abc.com/3"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/1", "abc.com/2", "abc.com/3"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com/2 abc.com/3]", New: "[abc.com/1 abc.com/2 abc.com/3]"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "swapped_sources",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com/1", "abc.com/3"}, TargetURL: "https://otherdomain.com"},
+						{SourceURLs: []string{"abc.com/2", "abc.com/4"}, TargetURL: "https://thirddomain.com"},
+					},
+				},
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com/1", "abc.com/4"),
+					existingRule("rule-2", "https://thirddomain.com", "abc.com/2", "abc.com/3"),
+				},
+			},
+			want: Plan{
+				Rules: []RuleChange{
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-1",
+						SourceURLs: []string{"abc.com/1", "abc.com/4"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/1"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com/1 abc.com/4]", New: "[abc.com/1]"},
+						},
+					},
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-2",
+						SourceURLs: []string{"abc.com/2", "abc.com/3"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/2"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com/2 abc.com/3]", New: "[abc.com/2]"},
+						},
+					},
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-1",
+						SourceURLs: []string{"abc.com/1", "abc.com/4"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/1", "abc.com/3"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com/1 abc.com/4]", New: "[abc.com/1 abc.com/3]"},
+						},
+					},
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-2",
+						SourceURLs: []string{"abc.com/2", "abc.com/3"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/2", "abc.com/4"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com/2 abc.com/3]", New: "[abc.com/2 abc.com/4]"},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "swapped_all_sources",
+			args: Args{
+				manifest: Manifest{
+					Rules: []Rule{
+						{SourceURLs: []string{"abc.com/1", "abc.com/2"}, TargetURL: "https://otherdomain.com"},
+						{SourceURLs: []string{"abc.com/3"}, TargetURL: "https://thirddomain.com"},
+					},
+				},
+				existing: []rule.Data{
+					existingRule("rule-1", "https://otherdomain.com", "abc.com/1", "abc.com/3"),
+					existingRule("rule-2", "https://thirddomain.com", "abc.com/2"),
+				},
+			},
+			want: Plan{
+				Rules: []RuleChange{
+					{Action: ActionDelete, ID: "rule-2", SourceURLs: []string{"abc.com/2"}},
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-1",
+						SourceURLs: []string{"abc.com/1", "abc.com/3"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/1"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com/1 abc.com/3]", New: "[abc.com/1]"},
+						},
+					},
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-1",
+						SourceURLs: []string{"abc.com/1", "abc.com/3"},
+						Attributes: rule.Attributes{
+							SourceURLs: []string{"abc.com/1", "abc.com/2"},
+						},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[abc.com/1 abc.com/3]", New: "[abc.com/1 abc.com/2]"},
+						},
+					},
+					{
+						Action:     ActionCreate,
+						SourceURLs: []string{"abc.com/3"},
+						Attributes: Rule{SourceURLs: []string{"abc.com/3"}, TargetURL: "https://thirddomain.com"}.attributes(),
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[]", New: "[abc.com/3]"},
+							{Field: "target_url", Old: `""`, New: `"https://thirddomain.com"`},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planRules(tt.args.manifest, tt.args.existing, tt.args.options, tt.args.prune)
+			td.Cmp(t, got, tt.want)
+			td.Cmp(t, got.HasChanges(), len(tt.want.Rules) > 0)
+		})
+	}
+}
+
+func TestPlanRulesMatchOptions(t *testing.T) {
+	var requests []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		requests = append(requests, fmt.Sprintf("%v %v", req.Method, req.URL.Path))
+
+		switch req.URL.Path {
+		case "/rules":
+			json.NewEncoder(w).Encode(rule.Rules{Data: []rule.Data{
+				existingRule("rule-1", "https://otherdomain.com", "abc.com/about"),
+			}})
+		case "/hosts":
+			w.Write([]byte(`{"data": [{"id": "host-1", "type": "host", "attributes": {"name": "abc.com"}}, {"id": "host-2", "type": "host", "attributes": {"name": "xyz.com"}}]}`))
+		default:
+			w.Write([]byte(`{"data": {"id": "host-1", "type": "host", "attributes": {"name": "abc.com", "match_options": {"case_insensitive": true}}}}`))
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	p, err := PlanRules(context.Background(), cl, Manifest{
+		Rules: []Rule{
+			{SourceURLs: []string{"abc.com/About"}, TargetURL: "https://otherdomain.com"},
+		},
+	}, false)
+	assert.Nil(t, err)
+	td.CmpFalse(t, p.HasChanges())
+	td.Cmp(t, requests, []string{"GET /rules", "GET /hosts", "GET /hosts/host-1"})
+}
+
+func TestPlanString(t *testing.T) {
+	tests := []struct {
+		name string
+		give Plan
+		want string
+	}{
+		{
+			name: "none",
+			give: Plan{},
+			want: "No changes.\n",
+		},
+		{
+			name: "changes",
+			give: Plan{
+				Rules: []RuleChange{
+					{Action: ActionDelete, ID: "rule-1", SourceURLs: []string{"abc.com"}},
+					{
+						Action:     ActionUpdate,
+						ID:         "rule-2",
+						SourceURLs: []string{"abc.com/1", "abc.com/2"},
+						Diff: []Diff{
+							{Field: "target_url", Old: `"a.com"`, New: `"b.com"`},
+						},
+					},
+					{
+						Action:     ActionCreate,
+						SourceURLs: []string{"abc.com/3"},
+						Diff: []Diff{
+							{Field: "source_urls", Old: "[]", New: "[abc.com/3]"},
+						},
+					},
+				},
+			},
+			want: heredoc.Doc(`
+				- delete rule rule-1 (abc.com)
+				~ update rule rule-2 (abc.com/1, abc.com/2)
+				    target_url: "a.com" => "b.com"
+				+ create rule abc.com/3
+				    source_urls: [] => [abc.com/3]
+
+				Plan: 1 to create, 1 to update, 1 to delete.
+			`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, tt.give.String(), tt.want)
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	type Fields struct {
+		status int
+	}
+
+	type Want struct {
+		requests []string
+		result   Result
+		err      string
+	}
+
+	plan := Plan{
+		Rules: []RuleChange{
+			{Action: ActionDelete, ID: "rule-1", SourceURLs: []string{"abc.com"}},
+			{
+				Action:     ActionUpdate,
+				ID:         "rule-2",
+				SourceURLs: []string{"abc.com/1"},
+				Attributes: rule.Attributes{TargetURL: ptr.String("https://newdomain.com")},
+			},
+			{
+				Action:     ActionCreate,
+				SourceURLs: []string{"abc.com/2"},
+				Attributes: rule.Attributes{SourceURLs: []string{"abc.com/2"}, TargetURL: ptr.String("https://newdomain.com")},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		fields Fields
+		want   Want
+	}{
+		{
+			name: "success",
+			fields: Fields{
+				status: http.StatusOK,
+			},
+			want: Want{
+				requests: []string{
+					`DELETE /rules/rule-1`,
+					`PATCH /rules/rule-2 {"target_url":"https://newdomain.com"}`,
+					`POST /rules {"source_urls":["abc.com/2"],"target_url":"https://newdomain.com"}`,
+				},
+				result: Result{Created: 1, Updated: 1, Deleted: 1},
+			},
+		},
+		{
+			name: "failure",
+			fields: Fields{
+				status: http.StatusNotFound,
+			},
+			want: Want{
+				requests: []string{
+					`DELETE /rules/rule-1`,
+				},
+				err: "unable to delete rule: rule-1 (abc.com)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+				body, _ := io.ReadAll(req.Body)
+				requests = append(requests, strings.TrimSpace(fmt.Sprintf("%v %v %s", req.Method, req.URL.Path, body)))
+
+				w.WriteHeader(tt.fields.status)
+				json.NewEncoder(w).Encode(map[string]any{
+					"data": map[string]any{"id": "rule-2", "type": "rule"},
+				})
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			got, err := Apply(context.Background(), cl, plan)
+			td.Cmp(t, requests, tt.want.requests)
+			if tt.want.err != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.err)
+				td.CmpTrue(t, errors.Is(err, client.ErrNotFound))
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, got, tt.want.result)
+		})
+	}
+}
+
+func ref[T any](x T) *T {
+	return &x
+}
@@ -1,54 +1,34 @@
 package rule
 
 import (
-	"bytes"
+	"context"
 	"fmt"
-	"net/http"
-	"strings"
 
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/resource"
 	"github.com/mikelorant/easyredir/pkg/easyredir/option"
-	"github.com/mikelorant/easyredir/pkg/jsonutil"
 )
 
 func CreateRule(cl ClientAPI, attr Attributes, opts ...option.Option) (r Rule, err error) {
-	r = Rule{}
-
-	o := &option.Options{}
-	for _, opt := range opts {
-		opt.Apply(o)
-	}
-
-	var b bytes.Buffer
-	if err := jsonutil.EncodeJSON(&attr, &b); err != nil {
-		return r, fmt.Errorf("unable to encode to json: %w", err)
-	}
+	return CreateRuleWithContext(context.Background(), cl, attr, opts...)
+}
 
-	pathQuery := buildCreateRule(o)
-	reader, err := cl.SendRequest(pathQuery, http.MethodPost, &b)
+func CreateRuleWithContext(ctx context.Context, cl ClientAPI, attr Attributes, opts ...option.Option) (r Rule, err error) {
+	o, err := option.New(option.FieldInclude|option.FieldSkipValidation, opts...)
 	if err != nil {
-		return r, fmt.Errorf("unable to send request: %w", err)
+		return r, fmt.Errorf("unable to apply options: %w", err)
 	}
 
-	if err := jsonutil.DecodeJSON(reader, &r); err != nil {
-		return r, fmt.Errorf("unable to get json: %w", err)
+	if !o.SkipValidation {
+		if err := attr.Validate(true); err != nil {
+			return r, fmt.Errorf("invalid rule: %w", err)
+		}
 	}
 
-	return r, nil
+	return resource.Create[Rule](ctx, cl, buildCreateRule(o), &attr)
 }
 
 func buildCreateRule(opts *option.Options) string {
-	var sb strings.Builder
-	var params []string
-
-	fmt.Fprint(&sb, "/rules")
-
-	if opts.Include != "" {
-		params = append(params, fmt.Sprintf("include[]=%v", opts.Include))
-	}
-
-	if len(params) != 0 {
-		fmt.Fprintf(&sb, "?%v", strings.Join(params, "&"))
-	}
-
-	return sb.String()
+	return resource.NewQuery("rules").
+		Add("include[]", opts.Include).
+		String()
 }
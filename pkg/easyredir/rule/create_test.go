@@ -8,12 +8,14 @@ import (
 	"github.com/gotidy/ptr"
 	"github.com/maxatome/go-testdeep/td"
 	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateRule(t *testing.T) {
 	type Args struct {
 		attributes Attributes
+		options    []option.Option
 	}
 	type Fields struct {
 		status int
@@ -120,6 +122,9 @@ func TestCreateRule(t *testing.T) {
 					SourceURLs:    []string{},
 					TargetURL:     ptr.String("otherdomain.com"),
 				},
+				options: []option.Option{
+					WithValidation(false),
+				},
 			},
 			fields: Fields{
 				status: http.StatusUnprocessableEntity,
@@ -157,7 +162,7 @@ func TestCreateRule(t *testing.T) {
 
 			cl := client.New(WithBaseURL(server.URL))
 
-			got, err := CreateRule(cl, tt.args.attributes)
+			got, err := CreateRule(cl, tt.args.attributes, tt.args.options...)
 			if tt.want.err != "" {
 				assert.NotNil(t, err)
 				td.CmpContains(t, err, tt.want.err)
@@ -0,0 +1,28 @@
+package rule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/resource"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+)
+
+func GetRule(cl ClientAPI, id string, opts ...option.Option) (r Rule, err error) {
+	return GetRuleWithContext(context.Background(), cl, id, opts...)
+}
+
+func GetRuleWithContext(ctx context.Context, cl ClientAPI, id string, opts ...option.Option) (r Rule, err error) {
+	o, err := option.New(option.FieldInclude, opts...)
+	if err != nil {
+		return r, fmt.Errorf("unable to apply options: %w", err)
+	}
+
+	return resource.Get[Rule](ctx, cl, buildGetRule(id, o))
+}
+
+func buildGetRule(id string, opts *option.Options) string {
+	return resource.NewQuery("rules", id).
+		Add("include[]", opts.Include).
+		String()
+}
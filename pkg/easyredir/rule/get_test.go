@@ -0,0 +1,181 @@
+package rule
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/stretchr/testify/assert"
+)
+
+type WithInclude string
+
+func (i WithInclude) Apply(o *option.Options) {
+	o.Include = string(i)
+}
+
+func TestGetRule(t *testing.T) {
+	type Args struct {
+		id      string
+		include string
+	}
+
+	type Fields struct {
+		status int
+		body   string
+	}
+
+	type Want struct {
+		rule  Rule
+		query string
+		err   string
+		kind  error
+	}
+
+	tests := []struct {
+		name   string
+		args   Args
+		fields Fields
+		want   Want
+	}{
+		{
+			name: "valid",
+			args: Args{
+				id: "abc-def",
+			},
+			fields: Fields{
+				status: http.StatusOK,
+				body: `
+					{
+					  "data": {
+					    "id": "abc-def",
+					    "type": "rule",
+					    "attributes": {
+					      "forward_params": true,
+					      "forward_path": true,
+					      "response_type": "moved_permanently",
+					      "source_urls": [
+					        "abc.com"
+					      ],
+					      "target_url": "otherdomain.com"
+					    }
+					  }
+					}
+				`,
+			},
+			want: Want{
+				rule: Rule{
+					Data: Data{
+						ID:   "abc-def",
+						Type: "rule",
+						Attributes: Attributes{
+							ForwardParams: ptr.Bool(true),
+							ForwardPath:   ptr.Bool(true),
+							ResponseType:  ref(ResponseMovedPermanently),
+							SourceURLs: []string{
+								"abc.com",
+							},
+							TargetURL: ptr.String("otherdomain.com"),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "include",
+			args: Args{
+				id:      "abc-def",
+				include: "source_hosts",
+			},
+			fields: Fields{
+				status: http.StatusOK,
+				body: `
+					{
+					  "data": {
+					    "id": "abc-def",
+					    "type": "rule"
+					  },
+					  "included": [
+					    {
+					      "id": "abc-123",
+					      "type": "host",
+					      "attributes": {
+					        "name": "abc.com"
+					      }
+					    }
+					  ]
+					}
+				`,
+			},
+			want: Want{
+				rule: Rule{
+					Data: Data{
+						ID:   "abc-def",
+						Type: "rule",
+					},
+					Included: []host.Data{
+						{
+							ID:   "abc-123",
+							Type: "host",
+							Attributes: host.Attributes{
+								Name: "abc.com",
+							},
+						},
+					},
+				},
+				query: "include%5B%5D=source_hosts",
+			},
+		},
+		{
+			name: "not_found",
+			args: Args{
+				id: "abc-def",
+			},
+			fields: Fields{
+				status: http.StatusNotFound,
+				body: `
+					{
+					  "type": "record_not_found_error",
+					  "message": "Record not found"
+					}
+				`,
+			},
+			want: Want{
+				err:  "record_not_found_error: Record not found",
+				kind: client.ErrNotFound,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/rules/", func(w http.ResponseWriter, req *http.Request) {
+				td.Cmp(t, req.URL.Path, "/rules/"+tt.args.id)
+				td.Cmp(t, req.URL.RawQuery, tt.want.query)
+				w.WriteHeader(tt.fields.status)
+				w.Write([]byte(tt.fields.body))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			got, err := GetRule(cl, tt.args.id, WithInclude(tt.args.include))
+			if tt.want.err != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.err)
+				td.CmpTrue(t, errors.Is(err, tt.want.kind))
+				return
+			}
+			assert.Nil(t, err)
+			td.Cmp(t, got, tt.want.rule)
+		})
+	}
+}
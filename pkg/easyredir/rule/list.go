@@ -1,101 +1,97 @@
 package rule
 
 import (
+	"context"
 	"fmt"
-	"net/http"
-	"strings"
 
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/resource"
 	"github.com/mikelorant/easyredir/pkg/easyredir/option"
-	"github.com/mikelorant/easyredir/pkg/jsonutil"
 )
 
+type Iterator = resource.Iterator[Data]
+
 func ListRulesPaginator(cl ClientAPI, opts ...option.Option) (r Rules, err error) {
-	r = Rules{
-		Data: []Data{},
-	}
+	return ListRulesPaginatorWithContext(context.Background(), cl, opts...)
+}
 
-	rules := Rules{}
-	for {
-		optsWithPage := opts
-		if rules.HasMore() {
-			optsWithPage = append(optsWithPage, rules.NextPage())
-		}
-
-		rules, err = ListRules(cl, optsWithPage...)
-		if err != nil {
-			return r, fmt.Errorf("unable to get a rules page: %w", err)
-		}
-		r.Data = append(r.Data, rules.Data...)
-		if !rules.HasMore() {
-			break
-		}
+func ListRulesPaginatorWithContext(ctx context.Context, cl ClientAPI, opts ...option.Option) (r Rules, err error) {
+	r.Data, err = resource.Paginate(ListRulesIterator(ctx, cl, opts...))
+
+	return r, err
+}
+
+func ListRulesIterator(ctx context.Context, cl ClientAPI, opts ...option.Option) *Iterator {
+	list := func(ctx context.Context, opts ...option.Option) (resource.Page[Data], error) {
+		r, err := ListRulesWithContext(ctx, cl, opts...)
+		return resource.Page[Data](r), err
 	}
 
-	return r, nil
+	return resource.NewIterator(ctx, "rules", list, Data.id, opts...)
 }
 
 func ListRules(cl ClientAPI, opts ...option.Option) (r Rules, err error) {
-	o := &option.Options{}
-	for _, opt := range opts {
-		opt.Apply(o)
-	}
+	return ListRulesWithContext(context.Background(), cl, opts...)
+}
 
-	pathQuery := buildListRules(o)
-	reader, err := cl.SendRequest(pathQuery, http.MethodGet, nil)
+func ListRulesWithContext(ctx context.Context, cl ClientAPI, opts ...option.Option) (r Rules, err error) {
+	o, err := option.New(option.FieldSourceFilter|option.FieldTargetFilter|option.FieldLimit|option.FieldPagination, opts...)
 	if err != nil {
-		return r, fmt.Errorf("unable to send request: %w", err)
-	}
-
-	if err := jsonutil.DecodeJSON(reader, &r); err != nil {
-		return r, fmt.Errorf("unable to get json: %w", err)
+		return r, fmt.Errorf("unable to apply options: %w", err)
 	}
 
-	return r, nil
-}
+	p, err := resource.List[Data](ctx, cl, buildListRules(o))
 
-func (r *Rules) NextPage() NextPage {
-	return NextPage(strings.Split((r.Links.Next), "=")[1])
+	return Rules(p), err
 }
 
-type NextPage string
-
-func (np NextPage) Apply(o *option.Options) {
-	o.Pagination.StartingAfter = string(np)
-}
+func (r *Rules) NextPage() (NextPage, error) {
+	c, err := r.Links.NextCursor()
+	if err != nil {
+		return "", fmt.Errorf("unable to get next page: %w", err)
+	}
 
-func (r *Rules) HasMore() bool {
-	return r.Metadata.HasMore
+	return NextPage(c), nil
 }
 
-func buildListRules(opts *option.Options) string {
-	var sb strings.Builder
-	var params []string
+func (r *Rules) PrevPage() (PrevPage, error) {
+	c, err := r.Links.PrevCursor()
+	if err != nil {
+		return "", fmt.Errorf("unable to get previous page: %w", err)
+	}
 
-	fmt.Fprint(&sb, "/rules")
+	return PrevPage(c), nil
+}
 
-	if opts.Pagination.StartingAfter != "" {
-		params = append(params, fmt.Sprintf("starting_after=%v", opts.Pagination.StartingAfter))
-	}
+type NextPage string
 
-	if opts.Pagination.EndingBefore != "" {
-		params = append(params, fmt.Sprintf("ending_before=%v", opts.Pagination.EndingBefore))
+func (np NextPage) Apply(o *option.Options) {
+	o.Pagination = option.Pagination{
+		StartingAfter: string(np),
 	}
+}
 
-	if opts.SourceFilter != "" {
-		params = append(params, fmt.Sprintf("sq=%v", opts.SourceFilter))
-	}
+type PrevPage string
 
-	if opts.TargetFilter != "" {
-		params = append(params, fmt.Sprintf("tq=%v", opts.TargetFilter))
+func (pp PrevPage) Apply(o *option.Options) {
+	o.Pagination = option.Pagination{
+		EndingBefore: string(pp),
 	}
+}
 
-	if opts.Limit != 0 {
-		params = append(params, fmt.Sprintf("limit=%v", opts.Limit))
-	}
+func (r *Rules) HasMore() bool {
+	return r.Metadata.HasMore
+}
 
-	if len(params) != 0 {
-		fmt.Fprintf(&sb, "?%v", strings.Join(params, "&"))
-	}
+func (d Data) id() string {
+	return d.ID
+}
 
-	return sb.String()
+func buildListRules(opts *option.Options) string {
+	return resource.NewQuery("rules").
+		Add("starting_after", opts.Pagination.StartingAfter).
+		Add("ending_before", opts.Pagination.EndingBefore).
+		Add("sq", opts.SourceFilter).
+		Add("tq", opts.TargetFilter).
+		AddInt("limit", opts.Limit).
+		String()
 }
@@ -1,6 +1,8 @@
 package rule
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -237,6 +239,40 @@ func TestListRulesPaginator(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "missing_next_link",
+			fields: Fields{
+				data: []MockData{
+					{
+						status: http.StatusOK,
+						body: `
+							{
+							  "data": [
+							    {
+							      "id": "abc-def",
+							      "type": "rule"
+							    }
+							  ],
+							  "meta": {
+								  "has_more": true
+							  }
+							}
+						`,
+					},
+				},
+			},
+			want: Want{
+				rules: Rules{
+					Data: []Data{
+						{
+							ID:   "abc-def",
+							Type: "rule",
+						},
+					},
+				},
+				err: "unable to get next page: no cursor",
+			},
+		},
 		{
 			name: "invalid_page",
 			fields: Fields{
@@ -373,7 +409,7 @@ func TestBuildListRules(t *testing.T) {
 				},
 			},
 			want: Want{
-				pathQuery: "/rules?sq=http://www1.example.org",
+				pathQuery: "/rules?sq=http%3A%2F%2Fwww1.example.org",
 			},
 		}, {
 			name: "target_filter",
@@ -383,7 +419,7 @@ func TestBuildListRules(t *testing.T) {
 				},
 			},
 			want: Want{
-				pathQuery: "/rules?tq=http://www2.example.org",
+				pathQuery: "/rules?tq=http%3A%2F%2Fwww2.example.org",
 			},
 		}, {
 			name: "source_target_filter",
@@ -394,7 +430,7 @@ func TestBuildListRules(t *testing.T) {
 				},
 			},
 			want: Want{
-				pathQuery: "/rules?sq=http://www1.example.org&tq=http://www2.example.org",
+				pathQuery: "/rules?sq=http%3A%2F%2Fwww1.example.org&tq=http%3A%2F%2Fwww2.example.org",
 			},
 		}, {
 			name: "limit",
@@ -419,7 +455,39 @@ func TestBuildListRules(t *testing.T) {
 				},
 			},
 			want: Want{
-				pathQuery: "/rules?starting_after=96b30ce8-6331-4c18-ae49-4155c3a2136c&sq=http://www1.example.org&tq=http://www2.example.org&limit=100",
+				pathQuery: "/rules?limit=100&sq=http%3A%2F%2Fwww1.example.org&starting_after=96b30ce8-6331-4c18-ae49-4155c3a2136c&tq=http%3A%2F%2Fwww2.example.org",
+			},
+		}, {
+			name: "hostile_source_filter",
+			args: Args{
+				options: &option.Options{
+					SourceFilter: "http://www1.example.org/a b?x=1&limit=5#top",
+				},
+			},
+			want: Want{
+				pathQuery: "/rules?sq=http%3A%2F%2Fwww1.example.org%2Fa+b%3Fx%3D1%26limit%3D5%23top",
+			},
+		}, {
+			name: "hostile_target_filter",
+			args: Args{
+				options: &option.Options{
+					TargetFilter: "&tq=other&",
+				},
+			},
+			want: Want{
+				pathQuery: "/rules?tq=%26tq%3Dother%26",
+			},
+		}, {
+			name: "hostile_cursor",
+			args: Args{
+				options: &option.Options{
+					Pagination: option.Pagination{
+						StartingAfter: "abc&sq=x",
+					},
+				},
+			},
+			want: Want{
+				pathQuery: "/rules?starting_after=abc%26sq%3Dx",
 			},
 		},
 	}
@@ -431,3 +499,365 @@ func TestBuildListRules(t *testing.T) {
 		})
 	}
 }
+
+func TestListRulesPaginatorWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rules/", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		cancel()
+
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`
+			{
+			  "data": [
+			    {
+			      "id": "abc-def",
+			      "type": "rule"
+			    }
+			  ],
+			  "meta": {
+			    "has_more": true
+			  },
+			  "links": {
+			    "next": "/v1/rules?starting_after=abc-def"
+			  }
+			}
+		`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	_, err := ListRulesPaginatorWithContext(ctx, cl)
+	assert.NotNil(t, err)
+	td.CmpTrue(t, errors.Is(err, context.Canceled))
+	td.Cmp(t, requests, 1)
+}
+
+func TestListRulesIterator(t *testing.T) {
+	pages := []string{
+		`
+			{
+			  "data": [
+			    { "id": "abc-def", "type": "rule" },
+			    { "id": "bcd-efg", "type": "rule" }
+			  ],
+			  "meta": { "has_more": true },
+			  "links": { "next": "/v1/rules?starting_after=bcd-efg" }
+			}
+		`,
+		`
+			{
+			  "data": [
+			    { "id": "cde-fgh", "type": "rule" }
+			  ]
+			}
+		`,
+	}
+
+	type Args struct {
+		limit int
+	}
+
+	type Want struct {
+		ids      []string
+		requests int
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "all",
+			want: Want{
+				ids:      []string{"abc-def", "bcd-efg", "cde-fgh"},
+				requests: 2,
+			},
+		},
+		{
+			name: "break_first_page",
+			args: Args{
+				limit: 1,
+			},
+			want: Want{
+				ids:      []string{"abc-def"},
+				requests: 1,
+			},
+		},
+		{
+			name: "break_second_page",
+			args: Args{
+				limit: 3,
+			},
+			want: Want{
+				ids:      []string{"abc-def", "bcd-efg", "cde-fgh"},
+				requests: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var queries []string
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/rules/", func(w http.ResponseWriter, req *http.Request) {
+				queries = append(queries, req.URL.RawQuery)
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(pages[len(queries)-1]))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			var ids []string
+			it := ListRulesIterator(context.Background(), cl)
+			for it.Next() {
+				ids = append(ids, it.Item().ID)
+				if len(ids) == tt.args.limit {
+					break
+				}
+			}
+			assert.Nil(t, it.Err())
+			td.Cmp(t, ids, tt.want.ids)
+			td.Cmp(t, len(queries), tt.want.requests)
+			if len(queries) > 1 {
+				td.Cmp(t, queries[1], "starting_after=bcd-efg")
+			}
+		})
+	}
+}
+
+func TestListRulesIteratorCursor(t *testing.T) {
+	pages := map[string]string{
+		"ending_before=zzz-zzz": `
+			{
+			  "data": [
+			    { "id": "ccc-ccc", "type": "rule" },
+			    { "id": "ddd-ddd", "type": "rule" }
+			  ],
+			  "meta": { "has_more": true },
+			  "links": { "prev": "/v1/rules?ending_before=ccc-ccc" }
+			}
+		`,
+		"ending_before=ccc-ccc": `
+			{
+			  "data": [
+			    { "id": "aaa-aaa", "type": "rule" },
+			    { "id": "bbb-bbb", "type": "rule" }
+			  ]
+			}
+		`,
+		"starting_after=bbb-bbb": `
+			{
+			  "data": [
+			    { "id": "ccc-ccc", "type": "rule" },
+			    { "id": "ddd-ddd", "type": "rule" }
+			  ]
+			}
+		`,
+	}
+
+	type Args struct {
+		cursor option.Pagination
+		limit  int
+	}
+
+	type Want struct {
+		ids    []string
+		cursor option.Pagination
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "backward",
+			args: Args{
+				cursor: option.Pagination{EndingBefore: "zzz-zzz"},
+			},
+			want: Want{
+				ids:    []string{"ddd-ddd", "ccc-ccc", "bbb-bbb", "aaa-aaa"},
+				cursor: option.Pagination{EndingBefore: "aaa-aaa"},
+			},
+		},
+		{
+			name: "backward_partial",
+			args: Args{
+				cursor: option.Pagination{EndingBefore: "zzz-zzz"},
+				limit:  2,
+			},
+			want: Want{
+				ids:    []string{"ddd-ddd", "ccc-ccc"},
+				cursor: option.Pagination{EndingBefore: "ccc-ccc"},
+			},
+		},
+		{
+			name: "resume_backward",
+			args: Args{
+				cursor: option.Pagination{EndingBefore: "ccc-ccc"},
+			},
+			want: Want{
+				ids:    []string{"bbb-bbb", "aaa-aaa"},
+				cursor: option.Pagination{EndingBefore: "aaa-aaa"},
+			},
+		},
+		{
+			name: "resume_forward",
+			args: Args{
+				cursor: option.Pagination{StartingAfter: "bbb-bbb"},
+			},
+			want: Want{
+				ids:    []string{"ccc-ccc", "ddd-ddd"},
+				cursor: option.Pagination{StartingAfter: "ddd-ddd"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/rules/", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(pages[req.URL.RawQuery]))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			var ids []string
+			it := ListRulesIterator(context.Background(), cl, tt.args.cursor)
+			for it.Next() {
+				ids = append(ids, it.Item().ID)
+				if len(ids) == tt.args.limit {
+					break
+				}
+			}
+			assert.Nil(t, it.Err())
+			td.Cmp(t, ids, tt.want.ids)
+			td.Cmp(t, it.Cursor(), tt.want.cursor)
+		})
+	}
+}
+
+func TestListRulesFilterEncoding(t *testing.T) {
+	tests := []struct {
+		name         string
+		sourceFilter string
+		targetFilter string
+	}{
+		{
+			name:         "query",
+			sourceFilter: "http://www1.example.org/?a=1&b=2",
+			targetFilter: "http://www2.example.org/?c=3",
+		},
+		{
+			name:         "fragment",
+			sourceFilter: "http://www1.example.org/#top",
+		},
+		{
+			name:         "spaces",
+			sourceFilter: "http://www1.example.org/a b",
+			targetFilter: "http://www2.example.org/ c+d",
+		},
+		{
+			name:         "percent",
+			sourceFilter: "http://www1.example.org/%20",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/rules", func(w http.ResponseWriter, req *http.Request) {
+				td.Cmp(t, req.URL.Query().Get("sq"), tt.sourceFilter)
+				td.Cmp(t, req.URL.Query().Get("tq"), tt.targetFilter)
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte("{}"))
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			cl := client.New(WithBaseURL(server.URL))
+
+			_, err := ListRules(cl, WithSourceFilter(tt.sourceFilter), WithTargetFilter(tt.targetFilter))
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestBuildRulePaths(t *testing.T) {
+	tests := []struct {
+		name string
+		give string
+		want string
+	}{
+		{
+			name: "get",
+			give: buildGetRule("abc/def?x#y", &option.Options{Include: "source_hosts"}),
+			want: "/rules/abc%2Fdef%3Fx%23y?include%5B%5D=source_hosts",
+		},
+		{
+			name: "update",
+			give: buildUpdateRule("../hosts", &option.Options{}),
+			want: "/rules/..%2Fhosts",
+		},
+		{
+			name: "remove",
+			give: buildRemoveRule("abc def"),
+			want: "/rules/abc%20def",
+		},
+		{
+			name: "create",
+			give: buildCreateRule(&option.Options{Include: "source_hosts&x=1"}),
+			want: "/rules?include%5B%5D=source_hosts%26x%3D1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, tt.give, tt.want)
+		})
+	}
+}
+
+func TestRulesUnsupportedOptions(t *testing.T) {
+	var requests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("{}"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	_, err := ListRules(cl, WithInclude("source_hosts"))
+	td.CmpTrue(t, errors.Is(err, option.ErrUnsupportedOption))
+
+	_, err = ListRulesPaginator(cl, WithBaseURL(server.URL))
+	td.CmpTrue(t, errors.Is(err, option.ErrUnsupportedOption))
+
+	_, err = CreateRule(cl, Attributes{}, WithSourceFilter("source.example.com"))
+	td.CmpTrue(t, errors.Is(err, option.ErrUnsupportedOption))
+
+	_, err = GetRule(cl, "abc-def", WithTargetFilter("target.example.com"))
+	td.CmpTrue(t, errors.Is(err, option.ErrUnsupportedOption))
+
+	td.Cmp(t, requests, 0)
+}
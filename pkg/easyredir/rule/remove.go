@@ -1,21 +1,23 @@
 package rule
 
 import (
-	"fmt"
-	"net/http"
+	"context"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/resource"
 )
 
 func RemoveRule(cl ClientAPI, id string) (res bool, err error) {
-	pathQuery := buildRemoveRule(id)
-	reader, err := cl.SendRequest(pathQuery, http.MethodDelete, nil)
-	if err != nil {
-		return false, fmt.Errorf("unable to send request: %w", err)
+	return RemoveRuleWithContext(context.Background(), cl, id)
+}
+
+func RemoveRuleWithContext(ctx context.Context, cl ClientAPI, id string) (res bool, err error) {
+	if err := resource.Remove(ctx, cl, buildRemoveRule(id)); err != nil {
+		return false, err
 	}
-	reader.Close()
 
 	return true, nil
 }
 
 func buildRemoveRule(id string) string {
-	return fmt.Sprintf("/rules/%v", id)
+	return resource.NewQuery("rules", id).String()
 }
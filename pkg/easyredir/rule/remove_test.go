@@ -1,6 +1,7 @@
 package rule
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -23,6 +24,7 @@ func TestRemoveRule(t *testing.T) {
 	type Want struct {
 		result bool
 		err    string
+		kind   error
 	}
 
 	tests := []struct {
@@ -60,6 +62,7 @@ func TestRemoveRule(t *testing.T) {
 			want: Want{
 				result: true,
 				err:    "record_not_found_error: Record not found",
+				kind:   client.ErrNotFound,
 			},
 		},
 	}
@@ -80,6 +83,7 @@ func TestRemoveRule(t *testing.T) {
 			if tt.want.err != "" {
 				assert.NotNil(t, err)
 				td.CmpContains(t, err, tt.want.err)
+				td.CmpTrue(t, errors.Is(err, tt.want.kind))
 				return
 			}
 			assert.Nil(t, err)
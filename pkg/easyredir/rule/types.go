@@ -109,7 +109,3 @@ func (r Rules) String() string {
 		return t.Render()
 	}
 }
-
-func ref[T any](x T) *T {
-	return &x
-}
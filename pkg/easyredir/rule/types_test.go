@@ -19,6 +19,18 @@ func (u WithBaseURL) Apply(o *option.Options) {
 	o.BaseURL = string(u)
 }
 
+type WithSourceFilter string
+
+func (s WithSourceFilter) Apply(o *option.Options) {
+	o.SourceFilter = string(s)
+}
+
+type WithTargetFilter string
+
+func (t WithTargetFilter) Apply(o *option.Options) {
+	o.TargetFilter = string(t)
+}
+
 func TestRulesDataStringer(t *testing.T) {
 	tests := []struct {
 		name string
@@ -152,3 +164,7 @@ func TestRulesStringer(t *testing.T) {
 		})
 	}
 }
+
+func ref[T any](x T) *T {
+	return &x
+}
@@ -0,0 +1,113 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/sourceurl"
+)
+
+// Validate checks the attributes, requiring source and target URLs on create.
+func (a Attributes) Validate(forCreate bool) error {
+	var errs []client.APIError
+
+	add := func(param string, code client.ErrorCode, format string, v ...any) {
+		errs = append(errs, client.APIError{
+			Resource: "rule",
+			Param:    param,
+			Code:     code,
+			Message:  fmt.Sprintf(format, v...),
+		})
+	}
+
+	if forCreate && len(a.SourceURLs) == 0 {
+		add("source_urls", client.ErrorCodeRequired, "at least one source URL is required")
+	}
+
+	for i, s := range a.SourceURLs {
+		param := fmt.Sprintf("source_urls[%d]", i)
+		if err := validateURL(s); err != nil {
+			add(param, client.ErrorCodeInvalid, "%v", err)
+			continue
+		}
+		if a.TargetURL != nil && sameURL(s, *a.TargetURL) {
+			add(param, client.ErrorCodeInvalid, "source URL must not equal the target URL: %v", s)
+		}
+	}
+
+	switch {
+	case a.TargetURL != nil:
+		if err := validateURL(*a.TargetURL); err != nil {
+			add("target_url", client.ErrorCodeInvalid, "%v", err)
+		}
+	case forCreate:
+		add("target_url", client.ErrorCodeRequired, "target URL is required")
+	}
+
+	if a.ResponseType != nil {
+		switch *a.ResponseType {
+		case ResponseMovedPermanently, ResponseFound:
+		default:
+			add("response_type", client.ErrorCodeInvalidOption, "unknown response type: %q", *a.ResponseType)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return client.APIErrors{
+		Type:    client.ErrorTypeInvalidRequest,
+		Message: "Invalid rule",
+		Errors:  errs,
+	}
+}
+
+// validateURL accepts URLs with or without a scheme but requires a host.
+func validateURL(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("URL must not be empty")
+	}
+
+	if strings.ContainsAny(s, " \t\r\n") {
+		return fmt.Errorf("URL must not contain whitespace: %q", s)
+	}
+
+	u, _, err := sourceurl.Parse(s)
+	if err != nil {
+		return fmt.Errorf("malformed URL: %q", s)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("URL is missing a host: %q", s)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL scheme must be http or https: %q", s)
+	}
+
+	return nil
+}
+
+// sameURL reports whether the URLs are the same. The scheme is only compared
+// when both URLs have one, as a source without a scheme matches either.
+func sameURL(a, b string) bool {
+	ua, sa, err := sourceurl.Parse(a)
+	if err != nil {
+		return false
+	}
+
+	ub, sb, err := sourceurl.Parse(b)
+	if err != nil {
+		return false
+	}
+
+	if sa && sb && !strings.EqualFold(ua.Scheme, ub.Scheme) {
+		return false
+	}
+
+	return strings.EqualFold(ua.Host, ub.Host) &&
+		strings.TrimSuffix(ua.Path, "/") == strings.TrimSuffix(ub.Path, "/") &&
+		ua.RawQuery == ub.RawQuery
+}
@@ -0,0 +1,169 @@
+package rule
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/client"
+	"github.com/mikelorant/easyredir/pkg/easyredir/option"
+	"github.com/stretchr/testify/assert"
+)
+
+type WithValidation bool
+
+func (v WithValidation) Apply(o *option.Options) {
+	o.SkipValidation = !bool(v)
+}
+
+func TestAttributesValidate(t *testing.T) {
+	type Args struct {
+		attributes Attributes
+		forCreate  bool
+	}
+
+	type Want struct {
+		errors []client.APIError
+	}
+
+	tests := []struct {
+		name string
+		args Args
+		want Want
+	}{
+		{
+			name: "valid_create",
+			args: Args{
+				attributes: Attributes{
+					ResponseType: ref(ResponseFound),
+					SourceURLs:   []string{"abc.com", "https://abc.com/123?x=1"},
+					TargetURL:    ptr.String("https://otherdomain.com"),
+				},
+				forCreate: true,
+			},
+		},
+		{
+			name: "valid_update",
+			args: Args{
+				attributes: Attributes{
+					ForwardPath: ptr.Bool(false),
+				},
+			},
+		},
+		{
+			name: "missing_create",
+			args: Args{
+				attributes: Attributes{},
+				forCreate:  true,
+			},
+			want: Want{
+				errors: []client.APIError{
+					{Resource: "rule", Param: "source_urls", Code: client.ErrorCodeRequired, Message: "at least one source URL is required"},
+					{Resource: "rule", Param: "target_url", Code: client.ErrorCodeRequired, Message: "target URL is required"},
+				},
+			},
+		},
+		{
+			name: "unknown_response_type",
+			args: Args{
+				attributes: Attributes{
+					ResponseType: ref(ResponseType("temporary")),
+				},
+			},
+			want: Want{
+				errors: []client.APIError{
+					{Resource: "rule", Param: "response_type", Code: client.ErrorCodeInvalidOption, Message: `unknown response type: "temporary"`},
+				},
+			},
+		},
+		{
+			name: "malformed_urls",
+			args: Args{
+				attributes: Attributes{
+					SourceURLs: []string{"abc.com", "", "abc.com/a b", "ftp://abc.com", "http://"},
+					TargetURL:  ptr.String("http://%zz"),
+				},
+			},
+			want: Want{
+				errors: []client.APIError{
+					{Resource: "rule", Param: "source_urls[1]", Code: client.ErrorCodeInvalid, Message: "URL must not be empty"},
+					{Resource: "rule", Param: "source_urls[2]", Code: client.ErrorCodeInvalid, Message: `URL must not contain whitespace: "abc.com/a b"`},
+					{Resource: "rule", Param: "source_urls[3]", Code: client.ErrorCodeInvalid, Message: `URL scheme must be http or https: "ftp://abc.com"`},
+					{Resource: "rule", Param: "source_urls[4]", Code: client.ErrorCodeInvalid, Message: `URL is missing a host: "http://"`},
+					{Resource: "rule", Param: "target_url", Code: client.ErrorCodeInvalid, Message: `malformed URL: "http://%zz"`},
+				},
+			},
+		},
+		{
+			name: "source_equals_target",
+			args: Args{
+				attributes: Attributes{
+					SourceURLs: []string{"abc.com/123", "HTTP://ABC.com/123/"},
+					TargetURL:  ptr.String("http://abc.com/123"),
+				},
+			},
+			want: Want{
+				errors: []client.APIError{
+					{Resource: "rule", Param: "source_urls[0]", Code: client.ErrorCodeInvalid, Message: "source URL must not equal the target URL: abc.com/123"},
+					{Resource: "rule", Param: "source_urls[1]", Code: client.ErrorCodeInvalid, Message: "source URL must not equal the target URL: HTTP://ABC.com/123/"},
+				},
+			},
+		},
+		{
+			name: "https_upgrade",
+			args: Args{
+				attributes: Attributes{
+					SourceURLs: []string{"http://abc.com/123"},
+					TargetURL:  ptr.String("https://abc.com/123"),
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.args.attributes.Validate(tt.args.forCreate)
+			if tt.want.errors == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			var apiErrs client.APIErrors
+			td.CmpTrue(t, errors.As(err, &apiErrs))
+			td.CmpTrue(t, errors.Is(err, client.ErrValidation))
+			td.Cmp(t, apiErrs.Errors, tt.want.errors)
+		})
+	}
+}
+
+func TestRuleValidation(t *testing.T) {
+	var requests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"data": {"id": "abc-def", "type": "rule"}}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cl := client.New(WithBaseURL(server.URL))
+
+	_, err := CreateRule(cl, Attributes{SourceURLs: []string{"abc.com"}})
+	td.CmpTrue(t, errors.Is(err, client.ErrValidation))
+	td.CmpContains(t, err, "invalid rule")
+
+	_, err = UpdateRule(cl, "abc-def", Attributes{TargetURL: ptr.String("")})
+	td.CmpTrue(t, errors.Is(err, client.ErrValidation))
+
+	td.Cmp(t, requests, 0)
+
+	_, err = CreateRule(cl, Attributes{SourceURLs: []string{"abc.com"}}, WithValidation(false))
+	assert.Nil(t, err)
+
+	td.Cmp(t, requests, 1)
+}
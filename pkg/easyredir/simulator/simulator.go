@@ -0,0 +1,220 @@
+package simulator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/internal/sourceurl"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+)
+
+// Simulator resolves URLs the way the redirect service would, using rules and
+// host settings that are already loaded, such as from an export.
+type Simulator struct {
+	rules []rule.Data
+	hosts map[string]host.Data
+}
+
+// Result is where a URL ends up. Rule is nil when no rule matched and the
+// host not found action was used. Host is nil when the host is unknown.
+type Result struct {
+	URL        string
+	Rule       *rule.Data
+	Host       *host.Data
+	StatusCode int
+	Location   string
+}
+
+func New(r rule.Rules, h host.Hosts) *Simulator {
+	s := &Simulator{
+		rules: r.Data,
+		hosts: make(map[string]host.Data),
+	}
+
+	for _, d := range h.Data {
+		s.hosts[strings.ToLower(d.Attributes.Name)] = d
+	}
+
+	return s
+}
+
+// Resolve finds the rule for the URL. An exact source match wins, otherwise
+// the longest source that is a path prefix of the URL is used as long as the
+// rule forwards the path. Without a match the host not found action applies.
+func (s *Simulator) Resolve(rawURL string) (res Result, err error) {
+	in, _, err := sourceurl.Parse(rawURL)
+	if err != nil {
+		return res, fmt.Errorf("unable to parse url: %v: %w", rawURL, err)
+	}
+	if in.Hostname() == "" {
+		return res, fmt.Errorf("url is missing a host: %v", rawURL)
+	}
+
+	res.URL = in.String()
+
+	name := strings.ToLower(in.Hostname())
+	mo := host.MatchOptions{}
+	if h, ok := s.hosts[name]; ok {
+		res.Host = &h
+		mo = h.Attributes.MatchOptions
+	}
+
+	r, rest, ok := s.match(in, mo)
+	if !ok {
+		s.notFound(&res, in)
+		return res, nil
+	}
+
+	res.Rule = &r
+	res.StatusCode = statusCode(r.Attributes.ResponseType)
+	res.Location = location(deref(r.Attributes.TargetURL), in, rest, deref(r.Attributes.ForwardPath), deref(r.Attributes.ForwardParams))
+
+	return res, nil
+}
+
+// match returns the rule for the URL and the rest of the path after its
+// source.
+func (s *Simulator) match(in *url.URL, mo host.MatchOptions) (found rule.Data, rest string, ok bool) {
+	path := sourceurl.NormalizePath(in.Path, mo)
+	best := -1
+
+	for _, r := range s.rules {
+		for _, src := range r.Attributes.SourceURLs {
+			su, scheme, err := sourceurl.Parse(src)
+			if err != nil || !strings.EqualFold(su.Hostname(), in.Hostname()) {
+				continue
+			}
+			if scheme && !strings.EqualFold(su.Scheme, in.Scheme) {
+				continue
+			}
+			if su.RawQuery != "" && su.Query().Encode() != in.Query().Encode() {
+				continue
+			}
+
+			sp := sourceurl.NormalizePath(su.Path, mo)
+
+			switch {
+			case sp == path:
+				return r, "", true
+			case deref(r.Attributes.ForwardPath) && isPrefix(sp, path) && len(sp) > best:
+				best = len(sp)
+				found = r
+				rest = remainder(in.Path, sp)
+				ok = true
+			}
+		}
+	}
+
+	return found, rest, ok
+}
+
+func (s *Simulator) notFound(res *Result, in *url.URL) {
+	res.StatusCode = http.StatusNotFound
+
+	if res.Host == nil {
+		return
+	}
+
+	nf := res.Host.Attributes.NotFoundAction
+	if nf.ResponseCode == nil || *nf.ResponseCode == host.ResponseCodeNotFound || deref(nf.ResponseURL) == "" {
+		return
+	}
+
+	res.StatusCode = int(*nf.ResponseCode)
+	res.Location = location(*nf.ResponseURL, in, in.Path, deref(nf.ForwardPath), deref(nf.ForwardParams))
+}
+
+// NormalizeSource returns the form of a source URL used for matching under
+// the host's match options. The scheme is only kept when one was given.
+func NormalizeSource(src string, mo host.MatchOptions) string {
+	return sourceurl.Normalize(src, mo)
+}
+
+// NormalizePath applies the match options to a path. The root path is always
+// "/".
+func NormalizePath(p string, mo host.MatchOptions) string {
+	return sourceurl.NormalizePath(p, mo)
+}
+
+func isPrefix(prefix, path string) bool {
+	if prefix == "/" {
+		return true
+	}
+
+	return strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/")
+}
+
+// remainder is the part of the original path after the source, keeping its
+// case.
+func remainder(path, prefix string) string {
+	n := len(strings.TrimSuffix(prefix, "/"))
+	if n > len(path) {
+		return ""
+	}
+
+	return path[n:]
+}
+
+// location builds the target, appending the forwarded path and query.
+func location(target string, in *url.URL, rest string, forwardPath, forwardParams bool) string {
+	t, _, err := sourceurl.Parse(target)
+	if err != nil {
+		return target
+	}
+
+	if forwardPath && rest != "" && rest != "/" {
+		t.Path = strings.TrimSuffix(t.Path, "/") + "/" + strings.TrimPrefix(rest, "/")
+	}
+
+	if forwardParams && in.RawQuery != "" {
+		if t.RawQuery == "" {
+			t.RawQuery = in.RawQuery
+		} else {
+			t.RawQuery += "&" + in.RawQuery
+		}
+	}
+
+	return t.String()
+}
+
+func statusCode(rt *rule.ResponseType) int {
+	if rt != nil && *rt == rule.ResponseFound {
+		return http.StatusFound
+	}
+
+	return http.StatusMovedPermanently
+}
+
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+
+	return *v
+}
+
+func (r Result) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "URL: %v\n", r.URL)
+
+	switch {
+	case r.Rule != nil:
+		fmt.Fprintf(&b, "Rule: %v (%v)\n", r.Rule.ID, strings.Join(r.Rule.Attributes.SourceURLs, ", "))
+	case r.Host != nil:
+		fmt.Fprintf(&b, "Rule: none, using not found action of host %v\n", r.Host.Attributes.Name)
+	default:
+		fmt.Fprintln(&b, "Rule: none, unknown host")
+	}
+
+	fmt.Fprintf(&b, "Status: %v\n", r.StatusCode)
+	if r.Location != "" {
+		fmt.Fprintf(&b, "Location: %v\n", r.Location)
+	}
+
+	return b.String()
+}
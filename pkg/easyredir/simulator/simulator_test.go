@@ -0,0 +1,221 @@
+package simulator
+
+import (
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/gotidy/ptr"
+	"github.com/maxatome/go-testdeep/td"
+	"github.com/mikelorant/easyredir/pkg/easyredir/host"
+	"github.com/mikelorant/easyredir/pkg/easyredir/rule"
+	"github.com/stretchr/testify/assert"
+)
+
+func rules() rule.Rules {
+	return rule.Rules{
+		Data: []rule.Data{
+			{
+				ID: "rule-1",
+				Attributes: rule.Attributes{
+					SourceURLs: []string{"abc.com/old", "abc.com/Legacy/"},
+					TargetURL:  ptr.String("https://new.com/new"),
+				},
+			},
+			{
+				ID: "rule-2",
+				Attributes: rule.Attributes{
+					ForwardParams: ptr.Bool(true),
+					ForwardPath:   ptr.Bool(true),
+					ResponseType:  ref(rule.ResponseFound),
+					SourceURLs:    []string{"abc.com/blog"},
+					TargetURL:     ptr.String("https://blog.new.com/?src=abc"),
+				},
+			},
+			{
+				ID: "rule-3",
+				Attributes: rule.Attributes{
+					ForwardPath: ptr.Bool(true),
+					SourceURLs:  []string{"abc.com/blog/archive"},
+					TargetURL:   ptr.String("archive.new.com"),
+				},
+			},
+			{
+				ID: "rule-4",
+				Attributes: rule.Attributes{
+					SourceURLs: []string{"https://xyz.com"},
+					TargetURL:  ptr.String("https://new.com"),
+				},
+			},
+		},
+	}
+}
+
+func hosts() host.Hosts {
+	return host.Hosts{
+		Data: []host.Data{
+			{
+				ID: "host-1",
+				Attributes: host.Attributes{
+					Name: "abc.com",
+					MatchOptions: host.MatchOptions{
+						CaseInsensitive:  ptr.Bool(true),
+						SlashInsensitive: ptr.Bool(true),
+					},
+					NotFoundAction: host.NotFoundAction{
+						ForwardParams: ptr.Bool(true),
+						ForwardPath:   ptr.Bool(true),
+						ResponseCode:  ref(host.ResponseCodeFound),
+						ResponseURL:   ptr.String("https://new.com/lost"),
+					},
+				},
+			},
+			{
+				ID: "host-2",
+				Attributes: host.Attributes{
+					Name: "xyz.com",
+					NotFoundAction: host.NotFoundAction{
+						ResponseCode: ref(host.ResponseCodeNotFound),
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	type Want struct {
+		rule     string
+		status   int
+		location string
+		err      string
+	}
+
+	tests := []struct {
+		name string
+		give string
+		want Want
+	}{
+		{
+			name: "exact",
+			give: "http://abc.com/old?x=1",
+			want: Want{rule: "rule-1", status: 301, location: "https://new.com/new"},
+		},
+		{
+			name: "case_and_slash_insensitive",
+			give: "abc.com/LEGACY",
+			want: Want{rule: "rule-1", status: 301, location: "https://new.com/new"},
+		},
+		{
+			name: "forward_path_and_params",
+			give: "abc.com/blog/2022/Post?utm=a",
+			want: Want{rule: "rule-2", status: 302, location: "https://blog.new.com/2022/Post?src=abc&utm=a"},
+		},
+		{
+			name: "longest_prefix",
+			give: "abc.com/blog/archive/2019",
+			want: Want{rule: "rule-3", status: 301, location: "http://archive.new.com/2019"},
+		},
+		{
+			name: "prefix_needs_segment",
+			give: "abc.com/blogroll",
+			want: Want{status: 302, location: "https://new.com/lost/blogroll"},
+		},
+		{
+			name: "scheme_mismatch",
+			give: "http://xyz.com",
+			want: Want{status: 404},
+		},
+		{
+			name: "scheme",
+			give: "https://XYZ.com/",
+			want: Want{rule: "rule-4", status: 301, location: "https://new.com"},
+		},
+		{
+			name: "not_found_forward",
+			give: "abc.com/missing?q=1",
+			want: Want{status: 302, location: "https://new.com/lost/missing?q=1"},
+		},
+		{
+			name: "unknown_host",
+			give: "other.com/old",
+			want: Want{status: 404},
+		},
+		{
+			name: "invalid",
+			give: "http://%zz",
+			want: Want{err: "unable to parse url"},
+		},
+	}
+
+	s := New(rules(), hosts())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Resolve(tt.give)
+			if tt.want.err != "" {
+				assert.NotNil(t, err)
+				td.CmpContains(t, err, tt.want.err)
+				return
+			}
+			assert.Nil(t, err)
+
+			if tt.want.rule == "" {
+				td.Cmp(t, got.Rule, td.Nil())
+			} else {
+				td.Cmp(t, got.Rule, td.Ptr(td.Smuggle("ID", tt.want.rule)))
+			}
+			td.Cmp(t, got.StatusCode, tt.want.status)
+			td.Cmp(t, got.Location, tt.want.location)
+		})
+	}
+}
+
+func TestNormalizeSource(t *testing.T) {
+	insensitive := host.MatchOptions{
+		CaseInsensitive:  ptr.Bool(true),
+		SlashInsensitive: ptr.Bool(true),
+	}
+
+	tests := []struct {
+		name string
+		give string
+		opts host.MatchOptions
+		want string
+	}{
+		{name: "root", give: "ABC.com", want: "abc.com/"},
+		{name: "sensitive", give: "abc.com/Path/", want: "abc.com/Path/"},
+		{name: "insensitive", give: "abc.com/Path/", opts: insensitive, want: "abc.com/path"},
+		{name: "scheme", give: "HTTPS://abc.com/a?b=1&a=2", want: "https://abc.com/a?a=2&b=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td.Cmp(t, NormalizeSource(tt.give, tt.opts), tt.want)
+		})
+	}
+}
+
+func TestResultString(t *testing.T) {
+	s := New(rules(), hosts())
+
+	got, err := s.Resolve("abc.com/blog/post")
+	assert.Nil(t, err)
+	td.Cmp(t, got.String(), heredoc.Doc(`
+		URL: http://abc.com/blog/post
+		Rule: rule-2 (abc.com/blog)
+		Status: 302
+		Location: https://blog.new.com/post?src=abc
+	`))
+
+	got, err = s.Resolve("other.com")
+	assert.Nil(t, err)
+	td.Cmp(t, got.String(), heredoc.Doc(`
+		URL: http://other.com
+		Rule: none, unknown host
+		Status: 404
+	`))
+}
+
+func ref[T any](x T) *T {
+	return &x
+}